@@ -1,50 +1,58 @@
 package main
 
 import (
+	"cmp"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
 
 	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/bundle"
+	"github.com/compose-network/local-testnet/internal/config"
+	"github.com/compose-network/local-testnet/internal/doctor"
 	"github.com/compose-network/local-testnet/internal/l1"
 	"github.com/compose-network/local-testnet/internal/l2"
 	"github.com/compose-network/local-testnet/internal/logger"
 	"github.com/compose-network/local-testnet/internal/observability"
+	"github.com/compose-network/local-testnet/internal/ports"
+	"github.com/compose-network/local-testnet/internal/up"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 const appName = "local-testnet"
 
+const (
+	logLevelFlag  = "log-level"
+	logFormatFlag = "log-format"
+	logFileFlag   = "log-file"
+	profileFlag   = "profile"
+	portModeFlag  = "port-mode"
+	instanceFlag  = "instance"
+	lockedFlag    = "locked"
+	offlineFlag   = "offline"
+)
+
 var rootCmd = &cobra.Command{
 	Use:   appName,
 	Short: "CLI for managing local L1 and L2 network",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		logger.Initialize(slog.LevelDebug)
-
-		viper.SetConfigName("config")
-		viper.SetConfigType("yaml")
+		if err := initLogger(cmd); err != nil {
+			return err
+		}
 
-		if execPath, err := os.Executable(); err == nil {
-			execDir := filepath.Dir(execPath)
-			viper.AddConfigPath(execDir)
+		if err := loadProfile(cmd); err != nil {
+			const errMsg = "error reading config file"
+			slog.With("err", err.Error()).Error(errMsg)
+			return errors.Join(err, errors.New(errMsg))
 		}
-		viper.AddConfigPath(".")
-		viper.AddConfigPath("./configs")
-
-		// Try to read config file, but don't fail if it doesn't exist
-		// Flags can provide all necessary configuration
-		if err := viper.ReadInConfig(); err != nil {
-			if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-				slog.Debug("no config file found, will rely on flags and defaults")
-			} else {
-				const errMsg = "error reading config file"
-				slog.With("err", err.Error()).Error(errMsg)
-				return errors.Join(err, errors.New(errMsg))
-			}
-		} else {
-			slog.With("config_file", viper.ConfigFileUsed()).Debug("config file loaded")
+
+		if err := configs.BindEnv(); err != nil {
+			const errMsg = "unable to bind environment variables"
+			slog.With("err", err.Error()).Error(errMsg)
+			return errors.Join(err, errors.New(errMsg))
 		}
 
 		if err := viper.Unmarshal(&configs.Values); err != nil {
@@ -53,21 +61,124 @@ var rootCmd = &cobra.Command{
 			return errors.Join(err, errors.New(errMsg))
 		}
 
+		mode, err := ports.ParseMode(string(configs.Values.Ports.Mode))
+		if err != nil {
+			return fmt.Errorf("invalid ports.mode: %w", err)
+		}
+		configs.Values.Ports.Mode = mode
+
+		if err := configs.ValidateInstance(configs.Values.Instance); err != nil {
+			return fmt.Errorf("invalid --%s: %w", instanceFlag, err)
+		}
+		// The enclave belongs to the instance too, whether its name comes from the config or --enclave-name.
+		if configs.Values.L1.Kurtosis.EnclaveName != "" {
+			configs.Values.L1.Kurtosis.EnclaveName = configs.ScopedName(configs.Values.L1.Kurtosis.EnclaveName)
+		}
+
 		slog.With("config", configs.Values).Debug("configuration loaded")
 
 		return nil
 	},
 }
 
+// loadProfile reads the selected profile (config.yaml by default) into viper.
+// Without --profile a missing config file is not an error, flags can provide all necessary configuration.
+func loadProfile(cmd *cobra.Command) error {
+	name, _ := cmd.Flags().GetString(profileFlag)
+	if !cmd.Flags().Changed(profileFlag) {
+		name = cmp.Or(os.Getenv(configs.EnvName(profileFlag)), name)
+	}
+
+	searchDirs := []string{".", "./configs"}
+	if execPath, err := os.Executable(); err == nil {
+		searchDirs = append([]string{filepath.Dir(execPath)}, searchDirs...)
+	}
+
+	profile, err := configs.LoadProfile(name, searchDirs)
+	if err != nil {
+		if name == "" && errors.Is(err, configs.ErrProfileNotFound) {
+			slog.Debug("no config file found, will rely on flags and defaults")
+			return nil
+		}
+		return err
+	}
+
+	if err := viper.MergeConfigMap(profile.Settings); err != nil {
+		return err
+	}
+	configs.ActiveProfile = profile
+	slog.With("profile", profile.Name, "config_files", profile.Files).Debug("config file loaded")
+
+	return nil
+}
+
+// initLogger configures the default logger from the persistent log flags.
+func initLogger(cmd *cobra.Command) error {
+	levelName, _ := cmd.Flags().GetString(logLevelFlag)
+	formatName, _ := cmd.Flags().GetString(logFormatFlag)
+	file, _ := cmd.Flags().GetString(logFileFlag)
+
+	level, err := logger.ParseLevel(levelName)
+	if err != nil {
+		return fmt.Errorf("invalid --%s: %w", logLevelFlag, err)
+	}
+	format, err := logger.ParseFormat(formatName)
+	if err != nil {
+		return fmt.Errorf("invalid --%s: %w", logFormatFlag, err)
+	}
+
+	return logger.Initialize(logger.Options{Level: level, Format: format, File: file})
+}
+
 func main() {
 	rootCmd.Short = appName
 
+	rootCmd.PersistentFlags().String(logLevelFlag, "debug", "Log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().String(logFormatFlag, string(logger.FormatJSON), "Log format on stderr (json, text, pretty)")
+	rootCmd.PersistentFlags().String(logFileFlag, "", "Also write JSON logs to this file")
+
+	rootCmd.PersistentFlags().String(profileFlag, "", "Configuration profile: a name resolved to config.<name>.yaml or a path to a YAML file (default config.yaml)")
+
+	rootCmd.PersistentFlags().String(portModeFlag, string(ports.ModeFixed), "Host port assignment: fixed fails when a port is taken, auto picks free ports and records them in the work directory")
+	if err := viper.BindPFlag("ports.mode", rootCmd.PersistentFlags().Lookup(portModeFlag)); err != nil {
+		slog.With("err", err.Error()).Error("failed to bind flag")
+		os.Exit(1)
+	}
+
+	rootCmd.PersistentFlags().String(instanceFlag, "", "Instance name prefixing the compose project, containers, networks, volumes, enclave and work directory, so several localnets can share a host")
+	if err := viper.BindPFlag(instanceFlag, rootCmd.PersistentFlags().Lookup(instanceFlag)); err != nil {
+		slog.With("err", err.Error()).Error("failed to bind flag")
+		os.Exit(1)
+	}
+
+	rootCmd.PersistentFlags().Bool(lockedFlag, false, "Pin repositories, images and the Kurtosis package to the versions recorded in localnet.lock instead of resolving them")
+	if err := viper.BindPFlag(lockedFlag, rootCmd.PersistentFlags().Lookup(lockedFlag)); err != nil {
+		slog.With("err", err.Error()).Error("failed to bind flag")
+		os.Exit(1)
+	}
+
+	rootCmd.PersistentFlags().Bool(offlineFlag, false, "Deploy the L2 from existing clones and images without network access, see bundle import")
+	if err := viper.BindPFlag(offlineFlag, rootCmd.PersistentFlags().Lookup(offlineFlag)); err != nil {
+		slog.With("err", err.Error()).Error("failed to bind flag")
+		os.Exit(1)
+	}
+
+	rootCmd.AddCommand(bundle.CMD)
+	rootCmd.AddCommand(config.CMD)
+	rootCmd.AddCommand(doctor.CMD)
 	rootCmd.AddCommand(l1.CMD)
 	rootCmd.AddCommand(l2.CMD)
 	rootCmd.AddCommand(observability.CMD)
+	rootCmd.AddCommand(up.CMD)
 
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if err != nil {
 		slog.With("err", err.Error()).Error("failed to execute root command")
+	}
+	if closeErr := logger.Close(); closeErr != nil {
+		fmt.Fprintln(os.Stderr, closeErr)
+	}
+	if err != nil {
 		panic(err.Error())
 	}
 }
@@ -3,6 +3,12 @@ package configs
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/compose-network/local-testnet/internal/lock"
+	"github.com/compose-network/local-testnet/internal/ports"
 )
 
 var Values Config
@@ -11,14 +17,44 @@ type (
 	RepositoryName string
 	L2ChainName    string
 	ImageName      string
+	L1Backend      string
 
 	Config struct {
+		// Instance isolates several localnets on one host, see ScopedName and WorkDir.
+		Instance      string        `mapstructure:"instance"`
 		L1            L1            `mapstructure:"l1"`
 		L2            L2            `mapstructure:"l2"`
 		Observability Observability `mapstructure:"observability"`
+		Ports         PortsConfig   `mapstructure:"ports"`
+		// Locked pins repositories, images and the Kurtosis package to the versions recorded in the lock file.
+		Locked bool `mapstructure:"locked"`
+		// Offline deploys the L2 from existing clones and images, see localnet bundle import.
+		Offline bool `mapstructure:"offline"`
+	}
+
+	// PortsConfig controls how the host ports of L2 and observability services are assigned.
+	PortsConfig struct {
+		Mode ports.Mode `mapstructure:"mode"`
 	}
 
 	L1 struct {
+		Backend  L1Backend      `mapstructure:"backend"`
+		Kurtosis KurtosisConfig `mapstructure:"kurtosis"`
+	}
+
+	// KurtosisConfig tunes the Kurtosis L1 backend. Overrides are merged onto the embedded
+	// params.yaml in this order: params-file, params, then the typed fields (zero values keep the default).
+	KurtosisConfig struct {
+		PackageName      string         `mapstructure:"package-name"`
+		EnclaveName      string         `mapstructure:"enclave-name"`
+		ParamsFile       string         `mapstructure:"params-file"`
+		ELImage          string         `mapstructure:"el-image"`
+		CLImage          string         `mapstructure:"cl-image"`
+		ParticipantCount int            `mapstructure:"participant-count"`
+		ValidatorCount   int            `mapstructure:"validator-count"`
+		SSVNodeCount     int            `mapstructure:"ssv-node-count"`
+		AnchorNodeCount  int            `mapstructure:"anchor-node-count"`
+		Params           map[string]any `mapstructure:"params"`
 	}
 
 	L2 struct {
@@ -37,6 +73,14 @@ type (
 		Blockscout            BlockscoutConfig              `mapstructure:"blockscout"`
 		Flashblocks           FlashblocksConfig             `mapstructure:"flashblocks"`
 		Sidecar               SidecarConfig                 `mapstructure:"sidecar"`
+
+		// HostPorts holds the host ports resolved for this run, keyed by ports.Name. It is not read
+		// from the config file: ports missing from it fall back to their configured or derived default.
+		HostPorts ports.Map `mapstructure:"-"`
+		// Lock holds the image digests and commits pinned by the lock file with --locked, and the op-rbuilder
+		// commit resolved for an unlocked run. It is not read from the config file: images it does not pin
+		// keep their configured reference.
+		Lock lock.File `mapstructure:"-"`
 	}
 
 	BlockscoutConfig struct {
@@ -47,14 +91,10 @@ type (
 		Enabled             bool   `mapstructure:"enabled"`
 		OpRbuilderImageTag  string `mapstructure:"op-rbuilder-image-tag"`
 		RollupBoostImageTag string `mapstructure:"rollup-boost-image-tag"`
-		RollupARPCPort      int    `mapstructure:"rollup-a-rpc-port"`
-		RollupBRPCPort      int    `mapstructure:"rollup-b-rpc-port"`
 	}
 
 	SidecarConfig struct {
-		Enabled        bool `mapstructure:"enabled"`
-		RollupAAPIPort int  `mapstructure:"rollup-a-api-port"`
-		RollupBAPIPort int  `mapstructure:"rollup-b-api-port"`
+		Enabled bool `mapstructure:"enabled"`
 	}
 
 	DisputeConfig struct {
@@ -72,8 +112,10 @@ type (
 	}
 
 	Chain struct {
-		ID      int `mapstructure:"id"`
-		RPCPort int `mapstructure:"rpc-port"`
+		ID                 int `mapstructure:"id"`
+		RPCPort            int `mapstructure:"rpc-port"`
+		FlashblocksRPCPort int `mapstructure:"flashblocks-rpc-port"`
+		SidecarAPIPort     int `mapstructure:"sidecar-api-port"`
 	}
 
 	Repository struct {
@@ -84,6 +126,9 @@ type (
 
 	Image struct {
 		Tag string `mapstructure:"tag"`
+		// Reference is a full image reference with a tag or digest. For op-geth, publisher and sidecar it
+		// replaces their repository: the image is pulled instead of built from source.
+		Reference string `mapstructure:"reference"`
 	}
 
 	Wallet struct {
@@ -96,6 +141,11 @@ type (
 )
 
 const (
+	// L1BackendKurtosis runs the ssv-mini Kurtosis package (multiple EL/CL pairs, SSV nodes, dora).
+	L1BackendKurtosis L1Backend = "kurtosis"
+	// L1BackendDev runs a single dev-mode geth container with a mock beacon API in front of it.
+	L1BackendDev L1Backend = "dev"
+
 	RepositoryNameOpGeth           RepositoryName = "op-geth"
 	RepositoryNamePublisher        RepositoryName = "publisher"
 	RepositoryNameComposeContracts RepositoryName = "compose-contracts"
@@ -105,11 +155,110 @@ const (
 	ImageNameOpNode     ImageName = "op-node"
 	ImageNameOpProposer ImageName = "op-proposer"
 	ImageNameOpBatcher  ImageName = "op-batcher"
+	// Images of the components otherwise built from their repository, see Image.Reference.
+	ImageNameOpGeth    ImageName = "op-geth"
+	ImageNamePublisher ImageName = "publisher"
+	ImageNameSidecar   ImageName = "sidecar"
 
-	L2ChainNameRollupA L2ChainName = "rollup-a"
-	L2ChainNameRollupB L2ChainName = "rollup-b"
+	// MaxL2Chains bounds the number of rollups, since each chain reserves its own
+	// 10000-wide block of host ports (chain #1 uses 1xxxx, chain #2 uses 2xxxx, ...).
+	MaxL2Chains = 5
 )
 
+// Suffix returns the short chain identifier used in service, container and volume names.
+// For example "rollup-a" becomes "a", so its execution client is named "op-geth-a".
+// Names without the "rollup-" prefix are returned as-is.
+func (n L2ChainName) Suffix() string {
+	return strings.TrimPrefix(string(n), "rollup-")
+}
+
+// EnvSuffix returns the chain suffix in a form usable inside environment variable names,
+// e.g. "rollup-a" becomes "A" (as in ROLLUP_A_CHAIN_ID).
+func (n L2ChainName) EnvSuffix() string {
+	return strings.ToUpper(strings.ReplaceAll(n.Suffix(), "-", "_"))
+}
+
+// ChainNames returns the configured L2 chain names in a stable, sorted order.
+// Every per-chain artifact (services, ports, output entries) follows this order.
+func (c *L2) ChainNames() []L2ChainName {
+	return slices.Sorted(maps.Keys(c.ChainConfigs))
+}
+
+// HostPortBase returns the first port of the host port block reserved for a chain.
+// The block is picked by the chain's position in ChainNames, so the first chain
+// exposes its auxiliary ports as 1xxxx, the second as 2xxxx, and so on.
+func (c *L2) HostPortBase(name L2ChainName) int {
+	return (slices.Index(c.ChainNames(), name) + 1) * 10000
+}
+
+// BuiltComponents returns the enabled components that run from a source repository or a prebuilt image.
+func (c *L2) BuiltComponents() []RepositoryName {
+	names := []RepositoryName{RepositoryNameOpGeth, RepositoryNamePublisher}
+	if c.Sidecar.Enabled {
+		names = append(names, RepositoryNameSidecar)
+	}
+	return names
+}
+
+// PrebuiltImage returns the image reference configured for the component built from repository name,
+// empty when it is built from source.
+func (c *L2) PrebuiltImage(name RepositoryName) string {
+	return c.Images[ImageName(name)].Reference
+}
+
+// SourceRepositories returns the repositories to clone or read from a local path: every configured
+// repository except those of components running a prebuilt image.
+func (c *L2) SourceRepositories() map[RepositoryName]Repository {
+	repositories := make(map[RepositoryName]Repository, len(c.Repositories))
+	for name, repo := range c.Repositories {
+		if c.PrebuiltImage(name) == "" {
+			repositories[name] = repo
+		}
+	}
+	return repositories
+}
+
+// BackendOrDefault returns the configured L1 backend, falling back to Kurtosis.
+func (c *L1) BackendOrDefault() L1Backend {
+	if c.Backend == "" {
+		return L1BackendKurtosis
+	}
+	return c.Backend
+}
+
+func (c *L1) Validate() error {
+	switch c.BackendOrDefault() {
+	case L1BackendDev:
+		return nil
+	case L1BackendKurtosis:
+	default:
+		return fmt.Errorf("l1.backend must be one of %s, %s (got '%s')", L1BackendKurtosis, L1BackendDev, c.Backend)
+	}
+
+	var errs []error
+
+	if c.Kurtosis.PackageName == "" {
+		errs = append(errs, errors.New("l1.kurtosis.package-name is required"))
+	}
+	if c.Kurtosis.EnclaveName == "" {
+		errs = append(errs, errors.New("l1.kurtosis.enclave-name is required"))
+	}
+
+	counts := map[string]int{
+		"participant-count": c.Kurtosis.ParticipantCount,
+		"validator-count":   c.Kurtosis.ValidatorCount,
+		"ssv-node-count":    c.Kurtosis.SSVNodeCount,
+		"anchor-node-count": c.Kurtosis.AnchorNodeCount,
+	}
+	for _, key := range slices.Sorted(maps.Keys(counts)) {
+		if counts[key] < 0 {
+			errs = append(errs, fmt.Errorf("l1.kurtosis.%s must not be negative", key))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 func (c *L2) Validate() error {
 	var errs []error
 
@@ -132,14 +281,13 @@ func (c *L2) Validate() error {
 		errs = append(errs, errors.New("l2.wallet.address is required"))
 	}
 
-	requiredRepos := []RepositoryName{RepositoryNameOpGeth, RepositoryNamePublisher}
-	if c.Sidecar.Enabled {
-		requiredRepos = append(requiredRepos, RepositoryNameSidecar)
-	}
-	for _, name := range requiredRepos {
+	for _, name := range c.BuiltComponents() {
+		if c.PrebuiltImage(name) != "" {
+			continue
+		}
 		repo, exists := c.Repositories[name]
 		if !exists {
-			errs = append(errs, fmt.Errorf("l2.repositories.%s is required", name))
+			errs = append(errs, fmt.Errorf("l2.repositories.%s or l2.images.%s.reference is required", name, name))
 			continue
 		}
 
@@ -165,28 +313,25 @@ func (c *L2) Validate() error {
 		}
 	}
 
-	rollupA, hasRollupA := c.ChainConfigs[L2ChainNameRollupA]
-	rollupB, hasRollupB := c.ChainConfigs[L2ChainNameRollupB]
-
-	if !hasRollupA {
-		errs = append(errs, errors.New("l2.chain-configs.rollup-a is required"))
-	} else {
-		if rollupA.ID == 0 {
-			errs = append(errs, errors.New("l2.chain-configs.rollup-a.id is required"))
+	if len(c.ChainConfigs) == 0 {
+		errs = append(errs, errors.New("l2.chain-configs must declare at least one chain"))
+	}
+	if len(c.ChainConfigs) > MaxL2Chains {
+		errs = append(errs, fmt.Errorf("l2.chain-configs supports at most %d chains, got %d", MaxL2Chains, len(c.ChainConfigs)))
+	}
+	for _, name := range c.ChainNames() {
+		chain := c.ChainConfigs[name]
+		if chain.ID == 0 {
+			errs = append(errs, fmt.Errorf("l2.chain-configs.%s.id is required", name))
 		}
-		if rollupA.RPCPort == 0 {
-			errs = append(errs, errors.New("l2.chain-configs.rollup-a.rpc-port is required"))
+		if chain.RPCPort == 0 {
+			errs = append(errs, fmt.Errorf("l2.chain-configs.%s.rpc-port is required", name))
 		}
-	}
-
-	if !hasRollupB {
-		errs = append(errs, errors.New("l2.chain-configs.rollup-b is required"))
-	} else {
-		if rollupB.ID == 0 {
-			errs = append(errs, errors.New("l2.chain-configs.rollup-b.id is required"))
+		if c.Flashblocks.Enabled && chain.FlashblocksRPCPort == 0 {
+			errs = append(errs, fmt.Errorf("l2.chain-configs.%s.flashblocks-rpc-port is required when flashblocks are enabled", name))
 		}
-		if rollupB.RPCPort == 0 {
-			errs = append(errs, errors.New("l2.chain-configs.rollup-b.rpc-port is required"))
+		if c.Sidecar.Enabled && chain.SidecarAPIPort == 0 {
+			errs = append(errs, fmt.Errorf("l2.chain-configs.%s.sidecar-api-port is required when sidecar is enabled", name))
 		}
 	}
 
@@ -229,6 +374,8 @@ func (c *L2) Validate() error {
 		errs = append(errs, errors.New("l2.compose-network-name is required"))
 	}
 
+	errs = append(errs, c.semanticErrors()...)
+
 	if len(errs) > 0 {
 		return fmt.Errorf("L2 configuration validation failed: %w", errors.Join(errs...))
 	}
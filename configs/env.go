@@ -0,0 +1,144 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/compose-network/local-testnet/internal/logger"
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix prefixes every environment variable read into the configuration.
+const EnvPrefix = "LOCALNET"
+
+// envFileSuffix marks variables holding the path of a file that contains a secret value.
+const envFileSuffix = "_FILE"
+
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// RepositoryNames and ImageNames list the well-known map keys, so their settings can be set from the environment
+// even when the config file does not mention them.
+var (
+	RepositoryNames = []RepositoryName{RepositoryNameOpGeth, RepositoryNamePublisher, RepositoryNameComposeContracts, RepositoryNameSidecar}
+	ImageNames      = []ImageName{ImageNameOpDeployer, ImageNameOpNode, ImageNameOpProposer, ImageNameOpBatcher, ImageNameOpGeth, ImageNamePublisher, ImageNameSidecar}
+)
+
+// EnvName returns the environment variable of a configuration key, e.g. l2.wallet.private-key
+// becomes LOCALNET_L2_WALLET_PRIVATE_KEY.
+func EnvName(key string) string {
+	return EnvPrefix + "_" + envKeyReplacer.Replace(strings.ToUpper(key))
+}
+
+// BindEnv makes every configuration key settable through a LOCALNET_* environment variable.
+// Precedence is flags > environment > config file > defaults. Secret keys also accept a
+// LOCALNET_*_FILE variable pointing to a file with the value, for keys mounted as files in CI.
+// Must run after the config file is read, so keys of named entries (e.g. chain configs) are known.
+func BindEnv() error {
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(envKeyReplacer)
+
+	keys := append(Keys(), viper.AllKeys()...)
+	slices.Sort(keys)
+
+	var errs []error
+	for _, key := range slices.Compact(keys) {
+		if logger.IsSecret(key) {
+			if err := loadSecretFile(key); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if err := viper.BindEnv(key); err != nil {
+			errs = append(errs, fmt.Errorf("failed to bind %s: %w", EnvName(key), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Keys returns the configuration keys declared by the Config struct. Maps of structs are expanded
+// with the entries of the loaded configuration and, for repositories and images, their well-known names.
+func Keys() []string {
+	return structKeys("", reflect.TypeOf(Config{}))
+}
+
+func structKeys(prefix string, t reflect.Type) []string {
+	var keys []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			keys = append(keys, structKeys(key, field.Type)...)
+		case reflect.Map:
+			for _, name := range mapKeyNames(key, field.Type.Key()) {
+				if field.Type.Elem().Kind() == reflect.Struct {
+					keys = append(keys, structKeys(key+"."+name, field.Type.Elem())...)
+				}
+			}
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// mapKeyNames returns the entries of a map key: those present in the loaded configuration
+// plus the well-known names of its key type.
+func mapKeyNames(key string, t reflect.Type) []string {
+	names := slices.Collect(maps.Keys(viper.GetStringMap(key)))
+	switch t {
+	case reflect.TypeFor[RepositoryName]():
+		for _, name := range RepositoryNames {
+			names = append(names, string(name))
+		}
+	case reflect.TypeFor[ImageName]():
+		for _, name := range ImageNames {
+			names = append(names, string(name))
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// loadSecretFile sets key to the content of LOCALNET_<KEY>_FILE. The value is merged over the config file
+// instead of being exported, so it does not leak into the environment of child processes, and it keeps
+// the precedence of the environment variable it replaces: below flags, above the config file.
+func loadSecretFile(key string) error {
+	envName := EnvName(key)
+	path, ok := os.LookupEnv(envName + envFileSuffix)
+	if !ok || path == "" {
+		return nil
+	}
+	if _, ok := os.LookupEnv(envName); ok {
+		return fmt.Errorf("both %s and %s are set, use only one", envName, envName+envFileSuffix)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", envName+envFileSuffix, err)
+	}
+
+	var value any = strings.TrimSpace(string(data))
+	parts := strings.Split(key, ".")
+	for i := len(parts) - 1; i >= 0; i-- {
+		value = map[string]any{parts[i]: value}
+	}
+	if err := viper.MergeConfigMap(value.(map[string]any)); err != nil {
+		return fmt.Errorf("failed to set %s from %s: %w", key, envName+envFileSuffix, err)
+	}
+	return nil
+}
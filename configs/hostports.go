@@ -0,0 +1,71 @@
+package configs
+
+import "fmt"
+
+// Host ports of the publisher, shared by all chains.
+const (
+	PublisherAPIPort     = 18080
+	PublisherMetricsPort = 18081
+)
+
+// Offsets of the host ports a chain publishes without a config key, added to its HostPortBase.
+const (
+	OpGethWSPortOffset           = 8546
+	OpGethAuthRPCPortOffset      = 8551
+	OpGethSequencerPortOffset    = 9898
+	OpNodeRPCPortOffset          = 9545
+	OpBatcherRPCPortOffset       = 8548
+	OpProposerRPCPortOffset      = 8560
+	OpRbuilderAuthRPCPortOffset  = 7552
+	OpRbuilderWSPortOffset       = 7111
+	OpRbuilderMetricsPortOffset  = 9001
+	RollupBoostAuthRPCPortOffset = 7551
+	RollupBoostDebugPortOffset   = 7555
+	RollupBoostSSEPortOffset     = 7999
+	BlockscoutPortOffset         = 9000
+)
+
+// derivedHostPort is a host port published by an enabled service that has no config key.
+type derivedHostPort struct {
+	description string
+	port        int
+}
+
+// derivedHostPorts lists the host ports of the enabled services that are not configured: those of the
+// publisher and those derived from the host port block of every chain.
+func (c *L2) derivedHostPorts() []derivedHostPort {
+	derived := []derivedHostPort{
+		{description: "the publisher API port", port: PublisherAPIPort},
+		{description: "the publisher metrics port", port: PublisherMetricsPort},
+	}
+
+	for _, name := range c.ChainNames() {
+		base := c.HostPortBase(name)
+		add := func(port string, offset int) {
+			derived = append(derived, derivedHostPort{
+				description: fmt.Sprintf("the %s port derived for l2.chain-configs.%s", port, name),
+				port:        base + offset,
+			})
+		}
+
+		add("op-geth WS", OpGethWSPortOffset)
+		add("op-geth auth RPC", OpGethAuthRPCPortOffset)
+		add("op-geth sequencer", OpGethSequencerPortOffset)
+		add("op-node RPC", OpNodeRPCPortOffset)
+		add("op-batcher RPC", OpBatcherRPCPortOffset)
+		add("op-proposer RPC", OpProposerRPCPortOffset)
+		if c.Flashblocks.Enabled {
+			add("op-rbuilder auth RPC", OpRbuilderAuthRPCPortOffset)
+			add("op-rbuilder flashblocks WS", OpRbuilderWSPortOffset)
+			add("op-rbuilder metrics", OpRbuilderMetricsPortOffset)
+			add("rollup-boost auth RPC", RollupBoostAuthRPCPortOffset)
+			add("rollup-boost debug", RollupBoostDebugPortOffset)
+			add("rollup-boost flashblocks SSE", RollupBoostSSEPortOffset)
+		}
+		if c.Blockscout.Enabled {
+			add("Blockscout", BlockscoutPortOffset)
+		}
+	}
+
+	return derived
+}
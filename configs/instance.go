@@ -0,0 +1,38 @@
+package configs
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// defaultWorkDir holds the generated files of the default instance, relative to the directory commands run from.
+const defaultWorkDir = ".localnet"
+
+// instanceNamePattern keeps instance names valid in compose project, container, network and volume names.
+var instanceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// ValidateInstance checks an instance name. Empty selects the default instance.
+func ValidateInstance(name string) error {
+	if name == "" || instanceNamePattern.MatchString(name) {
+		return nil
+	}
+	return fmt.Errorf("instance must be up to 32 lower-case letters, digits and dashes, starting with a letter or digit (got '%s')", name)
+}
+
+// ScopedName returns the name of a Docker or Kurtosis resource of the selected instance:
+// the name itself for the default instance, "<instance>-<name>" otherwise.
+func ScopedName(name string) string {
+	if Values.Instance == "" {
+		return name
+	}
+	return Values.Instance + "-" + name
+}
+
+// WorkDir returns the directory holding the generated files of the selected instance,
+// relative to the directory commands run from: .localnet, or .localnet-<instance>.
+func WorkDir() string {
+	if Values.Instance == "" {
+		return defaultWorkDir
+	}
+	return defaultWorkDir + "-" + Values.Instance
+}
@@ -0,0 +1,162 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExtendsKey names the profile a configuration file inherits from.
+const ExtendsKey = "extends"
+
+// ErrProfileNotFound is returned when no file matches a profile name or path.
+var ErrProfileNotFound = errors.New("profile not found")
+
+// ActiveProfile is the profile the configuration was loaded from.
+var ActiveProfile Profile
+
+// Profile is a configuration file merged over the profiles it extends.
+type Profile struct {
+	// Name is the requested profile, empty for the default config.yaml.
+	Name string
+	// Files lists the merged files from the base profile to the selected one.
+	Files []string
+	// Settings holds the merged configuration, without the extends key.
+	Settings map[string]any
+}
+
+// Path returns the file of the selected profile.
+func (p Profile) Path() string {
+	if len(p.Files) == 0 {
+		return ""
+	}
+	return p.Files[len(p.Files)-1]
+}
+
+// LoadProfile finds a profile in the search directories and merges it over the chain of profiles it extends.
+// A profile is either a path to a YAML file (relative to the working directory) or a name resolved to
+// config.<name>.yaml; an empty name selects config.yaml.
+// Maps are merged key by key, any other value (lists included) is replaced by the extending profile.
+func LoadProfile(name string, searchDirs []string) (Profile, error) {
+	path, err := findProfile(name, ".", searchDirs)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	profile := Profile{Name: name, Settings: map[string]any{}}
+	if err := profile.load(path, searchDirs); err != nil {
+		return Profile{}, err
+	}
+
+	return profile, nil
+}
+
+func (p *Profile) load(path string, searchDirs []string) error {
+	if slices.Contains(p.Files, path) {
+		return fmt.Errorf("profile %s extends itself: %s", path, strings.Join(append(p.Files, path), " -> "))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read profile %s: %w", path, err)
+	}
+
+	settings := map[string]any{}
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("failed to parse profile %s: %w", path, err)
+	}
+
+	base, hasBase := settings[ExtendsKey]
+	delete(settings, ExtendsKey)
+
+	if hasBase {
+		baseName, ok := base.(string)
+		if !ok || baseName == "" {
+			return fmt.Errorf("profile %s: '%s' must be a profile name or path", path, ExtendsKey)
+		}
+		// Bases are resolved relative to the extending file first.
+		dir := filepath.Dir(path)
+		basePath, err := findProfile(baseName, dir, append([]string{dir}, searchDirs...))
+		if err != nil {
+			return fmt.Errorf("profile %s: %w", path, err)
+		}
+		if err := p.load(basePath, searchDirs); err != nil {
+			return err
+		}
+	}
+
+	p.Files = append(p.Files, path)
+	mergeSettings(p.Settings, settings)
+
+	return nil
+}
+
+// findProfile resolves a profile reference. Paths are relative to baseDir, names are looked up in searchDirs.
+func findProfile(name, baseDir string, searchDirs []string) (string, error) {
+	var candidates []string
+	switch {
+	case name == "":
+		candidates = []string{"config.yaml", "config.yml"}
+	case isProfilePath(name):
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(baseDir, name)
+		}
+		return existingFile(name)
+	default:
+		candidates = []string{"config." + name + ".yaml", "config." + name + ".yml"}
+	}
+
+	for _, dir := range searchDirs {
+		for _, candidate := range candidates {
+			if path, err := existingFile(filepath.Join(dir, candidate)); err == nil {
+				return path, nil
+			}
+		}
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("%w: config.yaml in %s", ErrProfileNotFound, strings.Join(searchDirs, ", "))
+	}
+	return "", fmt.Errorf("%w: '%s' (looked for %s in %s)", ErrProfileNotFound, name, strings.Join(candidates, ", "), strings.Join(searchDirs, ", "))
+}
+
+func isProfilePath(name string) bool {
+	ext := filepath.Ext(name)
+	return strings.ContainsRune(name, filepath.Separator) || ext == ".yaml" || ext == ".yml"
+}
+
+func existingFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s", ErrProfileNotFound, path)
+		}
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%w: %s is a directory", ErrProfileNotFound, path)
+	}
+	return filepath.Abs(path)
+}
+
+// mergeSettings deep-merges src into dst; nested maps are merged, other values replaced.
+// Empty values (e.g. a bare "l1:") keep the inherited ones.
+func mergeSettings(dst, src map[string]any) {
+	for key, value := range src {
+		if _, exists := dst[key]; exists && value == nil {
+			continue
+		}
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeSettings(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
@@ -0,0 +1,80 @@
+package configs
+
+import (
+	"log/slog"
+
+	"github.com/compose-network/local-testnet/internal/logger"
+)
+
+// Aliases without methods, so LogValue can hand out redacted copies without recursing.
+type (
+	l2LogView     L2
+	configLogView Config
+)
+
+// LogValue implements slog.LogValuer, so logging the configuration never prints secrets.
+func (c Config) LogValue() slog.Value {
+	view := configLogView(c)
+	view.L2 = c.L2.Redacted()
+	return slog.AnyValue(view)
+}
+
+// LogValue implements slog.LogValuer, so logging the configuration never prints secrets.
+func (c L2) LogValue() slog.Value {
+	return slog.AnyValue(l2LogView(c.Redacted()))
+}
+
+// LogValue implements slog.LogValuer and hides the private key.
+func (w Wallet) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("address", w.Address),
+		slog.String("private-key", redact(w.PrivateKey)),
+	)
+}
+
+// Redacted returns a copy of the configuration with private keys and URL credentials masked.
+func (c L2) Redacted() L2 {
+	redacted := c
+	redacted.L1ElURL = logger.RedactURL(c.L1ElURL)
+	redacted.L1ClURL = logger.RedactURL(c.L1ClURL)
+	redacted.Wallet.PrivateKey = redact(c.Wallet.PrivateKey)
+	redacted.CoordinatorPrivateKey = redact(c.CoordinatorPrivateKey)
+
+	if c.Repositories != nil {
+		redacted.Repositories = make(map[RepositoryName]Repository, len(c.Repositories))
+		for name, repo := range c.Repositories {
+			repo.URL = logger.RedactURL(repo.URL)
+			redacted.Repositories[name] = repo
+		}
+	}
+
+	return redacted
+}
+
+// RedactSettings returns a copy of a settings map, as read from a config file, with the values of
+// secret keys and URL credentials masked.
+func RedactSettings(settings map[string]any) map[string]any {
+	redacted := make(map[string]any, len(settings))
+	for key, value := range settings {
+		switch v := value.(type) {
+		case map[string]any:
+			redacted[key] = RedactSettings(v)
+		case string:
+			redacted[key] = logger.RedactValue(key, v)
+		default:
+			// Hex keys without 0x prefix may be decoded as numbers.
+			if value != nil && logger.IsSecret(key) {
+				value = logger.Redacted
+			}
+			redacted[key] = value
+		}
+	}
+	return redacted
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return logger.Redacted
+}
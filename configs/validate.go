@@ -0,0 +1,250 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// chainNamePattern restricts chain names to what compose accepts in service, container and volume names.
+var chainNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// semanticErrors reports values that are present but inconsistent, each with the offending key path.
+// Missing values are reported by Validate, so empty fields are skipped here.
+func (c *L2) semanticErrors() []error {
+	var errs []error
+
+	errs = append(errs, c.chainNameErrors()...)
+	errs = append(errs, c.chainIDErrors()...)
+	errs = append(errs, c.portErrors()...)
+	errs = append(errs, c.keyErrors()...)
+	errs = append(errs, c.imageReferenceErrors()...)
+
+	if c.Sidecar.Enabled && !c.Flashblocks.Enabled {
+		errs = append(errs, errors.New("l2.sidecar.enabled requires l2.flashblocks.enabled"))
+	}
+
+	if c.GenesisBalanceWei != "" {
+		if balance, ok := new(big.Int).SetString(c.GenesisBalanceWei, 10); !ok || balance.Sign() < 0 {
+			errs = append(errs, fmt.Errorf("l2.genesis-balance-wei must be a non-negative decimal integer (got '%s')", c.GenesisBalanceWei))
+		}
+	}
+
+	return errs
+}
+
+// chainNameErrors checks that every chain name is usable in compose names, has a non-empty suffix and
+// that no two chains share a suffix, e.g. "rollup-a" and "a" would both run "op-geth-a".
+func (c *L2) chainNameErrors() []error {
+	var errs []error
+
+	seen := make(map[string]L2ChainName)
+	for _, name := range c.ChainNames() {
+		if !chainNamePattern.MatchString(string(name)) {
+			errs = append(errs, fmt.Errorf("l2.chain-configs.%s: chain names must match %s", name, chainNamePattern))
+			continue
+		}
+		if name.Suffix() == "" {
+			errs = append(errs, fmt.Errorf("l2.chain-configs.%s: chain names need a suffix after 'rollup-', e.g. 'rollup-a'", name))
+			continue
+		}
+		if other, exists := seen[name.Suffix()]; exists {
+			errs = append(errs, fmt.Errorf("l2.chain-configs.%s and l2.chain-configs.%s share the service suffix '%s'", other, name, name.Suffix()))
+			continue
+		}
+		seen[name.Suffix()] = name
+	}
+
+	return errs
+}
+
+func (c *L2) chainIDErrors() []error {
+	var errs []error
+
+	seen := make(map[int]L2ChainName)
+	for _, name := range c.ChainNames() {
+		id := c.ChainConfigs[name].ID
+		if id == 0 {
+			continue
+		}
+		if id == c.L1ChainID {
+			errs = append(errs, fmt.Errorf("l2.chain-configs.%s.id (%d) must differ from l2.l1-chain-id", name, id))
+		}
+		if other, exists := seen[id]; exists {
+			errs = append(errs, fmt.Errorf("l2.chain-configs.%s.id (%d) duplicates l2.chain-configs.%s.id", name, id, other))
+			continue
+		}
+		seen[id] = name
+	}
+
+	return errs
+}
+
+// portErrors checks the host ports of enabled services: configured ports must be valid, and no configured
+// port may collide with another one or with a port derived from the host port block of a chain.
+func (c *L2) portErrors() []error {
+	var errs []error
+
+	seen := make(map[int]string)
+	for _, derived := range c.derivedHostPorts() {
+		if other, exists := seen[derived.port]; exists {
+			errs = append(errs, fmt.Errorf("%s (%d) collides with %s", derived.description, derived.port, other))
+			continue
+		}
+		seen[derived.port] = derived.description
+	}
+
+	check := func(key string, port int) {
+		if port == 0 {
+			return
+		}
+		if port < 1 || port > 65535 {
+			errs = append(errs, fmt.Errorf("%s (%d) must be between 1 and 65535", key, port))
+			return
+		}
+		if other, exists := seen[port]; exists {
+			errs = append(errs, fmt.Errorf("%s (%d) collides with %s", key, port, other))
+			return
+		}
+		seen[port] = key
+	}
+
+	for _, name := range c.ChainNames() {
+		chain := c.ChainConfigs[name]
+		prefix := fmt.Sprintf("l2.chain-configs.%s.", name)
+		check(prefix+"rpc-port", chain.RPCPort)
+		if c.Flashblocks.Enabled {
+			check(prefix+"flashblocks-rpc-port", chain.FlashblocksRPCPort)
+		}
+		if c.Sidecar.Enabled {
+			check(prefix+"sidecar-api-port", chain.SidecarAPIPort)
+		}
+	}
+
+	return errs
+}
+
+// ValidateHostPorts reports invalid and colliding host ports. Validate checks them too, this lets a caller
+// fail on them before it starts anything the full validation depends on, such as the L1.
+func (c *L2) ValidateHostPorts() error {
+	return errors.Join(c.portErrors()...)
+}
+
+func (c *L2) keyErrors() []error {
+	var errs []error
+
+	if err := checkPrivateKey("l2.coordinator-private-key", c.CoordinatorPrivateKey); err != nil {
+		errs = append(errs, err)
+	}
+
+	walletKeyErr := checkPrivateKey("l2.wallet.private-key", c.Wallet.PrivateKey)
+	if walletKeyErr != nil {
+		errs = append(errs, walletKeyErr)
+	}
+	walletAddressErr := checkAddress("l2.wallet.address", c.Wallet.Address)
+	if walletAddressErr != nil {
+		errs = append(errs, walletAddressErr)
+	}
+	if walletKeyErr == nil && walletAddressErr == nil && c.Wallet.PrivateKey != "" && c.Wallet.Address != "" {
+		key, _ := crypto.HexToECDSA(strings.TrimPrefix(c.Wallet.PrivateKey, "0x"))
+		if derived := crypto.PubkeyToAddress(key.PublicKey); derived != common.HexToAddress(c.Wallet.Address) {
+			errs = append(errs, fmt.Errorf("l2.wallet.address (%s) does not match l2.wallet.private-key (which belongs to %s)", c.Wallet.Address, derived.Hex()))
+		}
+	}
+
+	addresses := []struct {
+		key   string
+		value string
+	}{
+		{"l2.dispute.verifier-address", c.Dispute.VerifierAddress},
+		{"l2.dispute.owner-address", c.Dispute.OwnerAddress},
+		{"l2.dispute.proposer-address", c.Dispute.ProposerAddress},
+		{"l2.dispute.guardian-address", c.Dispute.GuardianAddress},
+	}
+	for _, address := range addresses {
+		if err := checkAddress(address.key, address.value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.Dispute.AggregationVkey != "" {
+		if vkey := strings.TrimPrefix(c.Dispute.AggregationVkey, "0x"); len(vkey) != 64 || !isHex(vkey) {
+			errs = append(errs, errors.New("l2.dispute.aggregation-vkey must be 32 bytes of hex"))
+		}
+	}
+
+	return errs
+}
+
+// imageReferenceErrors checks the prebuilt images, which must name an exact version by tag or digest.
+func (c *L2) imageReferenceErrors() []error {
+	var errs []error
+
+	prebuildable := []ImageName{ImageNameOpGeth, ImageNamePublisher, ImageNameSidecar}
+	for _, name := range slices.Sorted(maps.Keys(c.Images)) {
+		value := c.Images[name].Reference
+		if value == "" {
+			continue
+		}
+		key := fmt.Sprintf("l2.images.%s.reference", name)
+		if !slices.Contains(prebuildable, name) {
+			errs = append(errs, fmt.Errorf("%s is only supported for %s, %s and %s, use tag", key, ImageNameOpGeth, ImageNamePublisher, ImageNameSidecar))
+			continue
+		}
+		named, err := reference.ParseNormalizedNamed(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s is not a valid image reference (got '%s'): %w", key, value, err))
+			continue
+		}
+		_, tagged := named.(reference.Tagged)
+		_, digested := named.(reference.Digested)
+		if !tagged && !digested {
+			errs = append(errs, fmt.Errorf("%s must include a tag or a digest (got '%s')", key, value))
+		}
+	}
+
+	return errs
+}
+
+// checkPrivateKey accepts 32 bytes of hex, with or without 0x prefix, forming a valid secp256k1 key.
+// The value is never included in the error.
+func checkPrivateKey(key, value string) error {
+	if value == "" {
+		return nil
+	}
+	hexKey := strings.TrimPrefix(value, "0x")
+	if len(hexKey) != 64 || !isHex(hexKey) {
+		return fmt.Errorf("%s must be 32 bytes of hex", key)
+	}
+	if _, err := crypto.HexToECDSA(hexKey); err != nil {
+		return fmt.Errorf("%s is not a valid secp256k1 private key", key)
+	}
+	return nil
+}
+
+func checkAddress(key, value string) error {
+	if value == "" {
+		return nil
+	}
+	if !strings.HasPrefix(value, "0x") || !common.IsHexAddress(value) {
+		return fmt.Errorf("%s must be a 0x-prefixed 20-byte hex address (got '%s')", key, value)
+	}
+	return nil
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
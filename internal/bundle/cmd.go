@@ -0,0 +1,50 @@
+package bundle
+
+import (
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/l2"
+	"github.com/spf13/cobra"
+)
+
+// defaultArchiveName is the archive written and read when none is given, scoped to the selected instance.
+const defaultArchiveName = "localnet-bundle.tar.gz"
+
+var CMD = &cobra.Command{
+	Use:   "bundle",
+	Short: "Export and import everything an L2 deployment downloads, for deployments without network access",
+	Long: `A bundle holds the Docker images, the cloned repositories including the forge libraries of
+compose-contracts, the op-deployer artifact cache and the solc compilers of forge.
+
+Export it on a machine that has run a deployment, import it on the offline machine and deploy
+with --offline. The L1 (Kurtosis) is not part of a bundle.`,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export [archive]",
+	Short: "Write the images, repositories and caches of the configured L2 deployment to an archive",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return l2.ExportBundle(cmd.Context(), configs.Values.L2, archivePath(args))
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import [archive]",
+	Short: "Load the images, repositories and caches of an archive written by bundle export",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return l2.ImportBundle(cmd.Context(), archivePath(args))
+	},
+}
+
+func archivePath(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return configs.ScopedName(defaultArchiveName)
+}
+
+func init() {
+	CMD.AddCommand(exportCmd)
+	CMD.AddCommand(importCmd)
+}
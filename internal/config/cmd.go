@@ -0,0 +1,15 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var CMD = &cobra.Command{
+	Use:   "config",
+	Short: "Create and inspect the configuration",
+}
+
+func init() {
+	CMD.AddCommand(initCmd)
+	CMD.AddCommand(showCmd)
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/l1"
+	"github.com/compose-network/local-testnet/internal/mnemonic"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	_ "embed"
+)
+
+//go:embed config.yaml.tmpl
+var configTemplate string
+
+const (
+	outputFlag         = "output"
+	forceFlag          = "force"
+	mnemonicFlag       = "mnemonic"
+	l1BackendFlag      = "l1-backend"
+	probeL1Flag        = "probe-l1"
+	enclaveNameFlag    = "enclave-name"
+	l1EndpointHostFlag = "l1-endpoint-host"
+
+	// localVerifierAddress is a placeholder SP1 verifier, no verifier is deployed on local L1 networks.
+	localVerifierAddress = "0x0000000000000000000000000000000000000001"
+
+	// Chain ID of the ethereum-package 'kurtosis' network and of geth dev mode.
+	kurtosisL1ChainID = 3151908
+	devL1ChainID      = 1337
+
+	// Account indexes derived from a mnemonic.
+	walletAccountIndex      = 0
+	coordinatorAccountIndex = 1
+)
+
+type initValues struct {
+	L1Backend             configs.L1Backend
+	PackageName           string
+	EnclaveName           string
+	L1Probed              bool
+	L1ChainID             int
+	L1ElURL               string
+	L1ClURL               string
+	WalletPrivateKey      string
+	WalletAddress         string
+	CoordinatorPrivateKey string
+	CoordinatorAddress    string
+	VerifierAddress       string
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a complete configuration for a local network",
+	Long: `Writes a configuration that passes validation as is: fresh wallet and coordinator keys (or keys
+derived from --mnemonic, accounts 0 and 1 of m/44'/60'/0'/0), local dispute defaults owned by the
+wallet and public repository URLs. With the kurtosis backend the wallet is prefunded in the L1 genesis.
+
+L1 endpoints are placeholders replaced by 'localnet up', unless --probe-l1 reads them from a running enclave.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString(outputFlag)
+		force, _ := cmd.Flags().GetBool(forceFlag)
+		mnemonic, _ := cmd.Flags().GetString(mnemonicFlag)
+		backend, _ := cmd.Flags().GetString(l1BackendFlag)
+		probe, _ := cmd.Flags().GetBool(probeL1Flag)
+		enclaveName, _ := cmd.Flags().GetString(enclaveNameFlag)
+		endpointHost, _ := cmd.Flags().GetString(l1EndpointHostFlag)
+
+		if mnemonic == "" {
+			mnemonic = os.Getenv(configs.EnvName(mnemonicFlag))
+		}
+
+		if _, err := os.Stat(output); err == nil && !force {
+			return fmt.Errorf("%s already exists, pass --%s to overwrite it", output, forceFlag)
+		}
+
+		values := initValues{
+			L1Backend:       configs.L1Backend(backend),
+			PackageName:     l1.DefaultPackageName,
+			EnclaveName:     enclaveName,
+			VerifierAddress: localVerifierAddress,
+		}
+
+		switch values.L1Backend {
+		case configs.L1BackendKurtosis:
+			values.L1ChainID = kurtosisL1ChainID
+			values.L1ElURL = fmt.Sprintf("http://%s:8545", endpointHost)
+			values.L1ClURL = fmt.Sprintf("http://%s:4000", endpointHost)
+		case configs.L1BackendDev:
+			if probe {
+				return fmt.Errorf("--%s requires the %s backend", probeL1Flag, configs.L1BackendKurtosis)
+			}
+			values.L1ChainID = devL1ChainID
+			values.L1ElURL = fmt.Sprintf("http://%s:8545", endpointHost)
+			values.L1ClURL = fmt.Sprintf("http://%s:5052", endpointHost)
+		default:
+			return fmt.Errorf("--%s must be one of %s, %s (got '%s')", l1BackendFlag, configs.L1BackendKurtosis, configs.L1BackendDev, backend)
+		}
+
+		if probe {
+			slog.With("enclave", enclaveName).Info("reading L1 endpoints from the running enclave")
+			l1Output, err := l1.Probe(cmd.Context(), enclaveName, endpointHost)
+			if err != nil {
+				return fmt.Errorf("failed to probe enclave %s: %w", enclaveName, err)
+			}
+			values.L1Probed = true
+			values.L1ChainID = l1Output.ChainID
+			values.L1ElURL = l1Output.ELRPCURL
+			values.L1ClURL = l1Output.CLBeaconURL
+		}
+
+		wallet, coordinator, err := initKeys(mnemonic)
+		if err != nil {
+			return err
+		}
+		values.WalletPrivateKey = hexutil.Encode(crypto.FromECDSA(wallet))[2:]
+		values.WalletAddress = crypto.PubkeyToAddress(wallet.PublicKey).Hex()
+		values.CoordinatorPrivateKey = hexutil.Encode(crypto.FromECDSA(coordinator))[2:]
+		values.CoordinatorAddress = crypto.PubkeyToAddress(coordinator.PublicKey).Hex()
+
+		content, err := renderConfig(values)
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", output, err)
+		}
+		if err := os.WriteFile(output, content, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+
+		slog.With("path", output, "wallet", values.WalletAddress, "coordinator", values.CoordinatorAddress, "l1_backend", values.L1Backend).
+			Info("configuration written")
+
+		return nil
+	},
+}
+
+// initKeys returns the wallet and coordinator keys, derived from the mnemonic when one is given.
+func initKeys(words string) (wallet, coordinator *ecdsa.PrivateKey, err error) {
+	if words == "" {
+		if wallet, err = crypto.GenerateKey(); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate wallet key: %w", err)
+		}
+		if coordinator, err = crypto.GenerateKey(); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate coordinator key: %w", err)
+		}
+		return wallet, coordinator, nil
+	}
+
+	if wallet, err = mnemonic.DeriveKey(words, walletAccountIndex); err != nil {
+		return nil, nil, fmt.Errorf("failed to derive wallet key: %w", err)
+	}
+	if coordinator, err = mnemonic.DeriveKey(words, coordinatorAccountIndex); err != nil {
+		return nil, nil, fmt.Errorf("failed to derive coordinator key: %w", err)
+	}
+	return wallet, coordinator, nil
+}
+
+// renderConfig renders the configuration and checks it passes validation.
+func renderConfig(values initValues) ([]byte, error) {
+	tmpl, err := template.New("config").Parse(configTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return nil, fmt.Errorf("failed to execute config template: %w", err)
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(buf.Bytes())); err != nil {
+		return nil, fmt.Errorf("generated configuration is not valid YAML: %w", err)
+	}
+	var cfg configs.Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode generated configuration: %w", err)
+	}
+	if err := errors.Join(cfg.L1.Validate(), cfg.L2.Validate()); err != nil {
+		return nil, fmt.Errorf("generated configuration is invalid: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func init() {
+	initCmd.Flags().String(outputFlag, "configs/config.yaml", "Path of the configuration file to write")
+	initCmd.Flags().Bool(forceFlag, false, "Overwrite an existing configuration file")
+	initCmd.Flags().String(mnemonicFlag, "", "Derive the wallet and coordinator keys from this BIP-39 mnemonic (or LOCALNET_MNEMONIC) instead of generating them")
+	initCmd.Flags().String(l1BackendFlag, string(configs.L1BackendKurtosis), "L1 backend: kurtosis or dev")
+	initCmd.Flags().Bool(probeL1Flag, false, "Read the L1 chain ID and endpoints from a running Kurtosis enclave")
+	initCmd.Flags().String(enclaveNameFlag, l1.DefaultEnclaveName, "Kurtosis enclave name")
+	initCmd.Flags().String(l1EndpointHostFlag, l1.DefaultEndpointHost, "Host name put into the L1 endpoint URLs")
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const effectiveFlag = "effective"
+
+var showCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the selected configuration profile with secrets redacted",
+	Long: `Prints the selected profile file as written. With --effective, prints the configuration the
+other commands run with: the profile merged over the profiles it extends, with defaults and
+LOCALNET_* environment overrides applied.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		effective, _ := cmd.Flags().GetBool(effectiveFlag)
+
+		profile := configs.ActiveProfile
+		out := cmd.OutOrStdout()
+
+		var settings map[string]any
+		if effective {
+			settings = viper.AllSettings()
+			if len(profile.Files) > 0 {
+				fmt.Fprintf(out, "# merged from: %s\n", strings.Join(profile.Files, ", "))
+			}
+		} else {
+			if profile.Path() == "" {
+				return fmt.Errorf("no config file loaded")
+			}
+			data, err := os.ReadFile(profile.Path())
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", profile.Path(), err)
+			}
+			if err := yaml.Unmarshal(data, &settings); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", profile.Path(), err)
+			}
+			fmt.Fprintf(out, "# %s\n", profile.Path())
+		}
+
+		encoder := yaml.NewEncoder(out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(configs.RedactSettings(settings)); err != nil {
+			return fmt.Errorf("failed to encode configuration: %w", err)
+		}
+		return encoder.Close()
+	},
+}
+
+func init() {
+	showCmd.Flags().Bool(effectiveFlag, false, "Print the merged configuration including inherited profiles and environment overrides")
+}
@@ -0,0 +1,258 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/compose-network/local-testnet/internal/logger"
+	"github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/build"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/system"
+	"github.com/docker/docker/client"
+	"github.com/moby/go-archive"
+)
+
+// SourceLabel is the label of locally built images holding the revision of the source they were built from,
+// see git.Cloner.SourceRevision. An image whose label matches the checkout is not built again.
+const SourceLabel = "com.compose-network.localnet.source"
+
+type Client struct {
+	cli    *client.Client
+	logger *slog.Logger
+}
+
+// New creates a new Docker client.
+func New() (*Client, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{cli: cli, logger: logger.Named("docker_client")}, nil
+}
+
+// Close closes the Docker client connection.
+func (c *Client) Close() error {
+	return c.cli.Close()
+}
+
+// ServerVersion returns the version of the Docker daemon. It fails when the daemon is unreachable.
+func (c *Client) ServerVersion(ctx context.Context) (types.Version, error) {
+	return c.cli.ServerVersion(ctx)
+}
+
+// Info returns system-wide information about the Docker daemon.
+func (c *Client) Info(ctx context.Context) (system.Info, error) {
+	return c.cli.Info(ctx)
+}
+
+// ImageExists checks if a Docker image exists locally.
+func (c *Client) ImageExists(ctx context.Context, imageName string) (bool, error) {
+	_, err := c.cli.ImageInspect(ctx, imageName)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ImageBuiltFrom reports whether the local image imageName exists and was built from the source revision,
+// see SourceLabel. An empty revision never matches.
+func (c *Client) ImageBuiltFrom(ctx context.Context, imageName, revision string) (bool, error) {
+	if revision == "" {
+		return false, nil
+	}
+
+	inspect, err := c.cli.ImageInspect(ctx, imageName)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if inspect.Config == nil {
+		return false, nil
+	}
+
+	return inspect.Config.Labels[SourceLabel] == revision, nil
+}
+
+// PullImage pulls a Docker image from a registry.
+func (c *Client) PullImage(ctx context.Context, imageName string) error {
+	c.logger.With("image", imageName).Info("pulling docker image")
+
+	resp, err := c.cli.ImagePull(ctx, imageName, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	defer resp.Close()
+
+	scanner := bufio.NewScanner(resp)
+	var pullError error
+	for scanner.Scan() {
+		line := scanner.Text()
+		c.logger.Debug(line)
+
+		var msg struct {
+			Error       string `json:"error"`
+			ErrorDetail struct {
+				Message string `json:"message"`
+			} `json:"errorDetail"`
+		}
+		if err := json.Unmarshal([]byte(line), &msg); err == nil {
+			if msg.Error != "" {
+				pullError = fmt.Errorf("pull failed: %s", msg.Error)
+				c.logger.Error("docker pull error", "error", msg.Error)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading pull output: %w", err)
+	}
+
+	if pullError != nil {
+		return pullError
+	}
+
+	c.logger.With("image", imageName).Info("docker image pulled successfully")
+	return nil
+}
+
+// ImageDigest returns the registry digest reference ("repository@sha256:...") of a local image pulled as imageName.
+// Images built locally have no registry digest and yield an error.
+func (c *Client) ImageDigest(ctx context.Context, imageName string) (string, error) {
+	inspect, err := c.cli.ImageInspect(ctx, imageName)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image '%s': %w", imageName, err)
+	}
+
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference '%s': %w", imageName, err)
+	}
+	for _, repoDigest := range inspect.RepoDigests {
+		digested, err := reference.ParseNormalizedNamed(repoDigest)
+		if err == nil && digested.Name() == named.Name() {
+			return repoDigest, nil
+		}
+	}
+
+	return "", fmt.Errorf("image '%s' has no registry digest", imageName)
+}
+
+// SaveImages writes the given images, with their tags, to w as a tar archive (docker save).
+func (c *Client) SaveImages(ctx context.Context, imageNames []string, w io.Writer) error {
+	c.logger.With("images", imageNames).Info("saving docker images")
+
+	resp, err := c.cli.ImageSave(ctx, imageNames)
+	if err != nil {
+		return fmt.Errorf("failed to save images: %w", err)
+	}
+	defer resp.Close()
+
+	if _, err := io.Copy(w, resp); err != nil {
+		return fmt.Errorf("failed to save images: %w", err)
+	}
+	return nil
+}
+
+// LoadImages loads the images of a tar archive written by SaveImages (docker load).
+func (c *Client) LoadImages(ctx context.Context, r io.Reader) error {
+	resp, err := c.cli.ImageLoad(ctx, r, client.ImageLoadWithQuiet(true))
+	if err != nil {
+		return fmt.Errorf("failed to load images: %w", err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	var loadError error
+	for scanner.Scan() {
+		line := scanner.Text()
+		c.logger.Debug(line)
+
+		var msg struct {
+			Error  string `json:"error"`
+			Stream string `json:"stream"`
+		}
+		if err := json.Unmarshal([]byte(line), &msg); err == nil {
+			if msg.Error != "" {
+				loadError = fmt.Errorf("load failed: %s", msg.Error)
+				c.logger.Error("docker load error", "error", msg.Error)
+			}
+			if stream := strings.TrimSpace(msg.Stream); stream != "" {
+				c.logger.Info(stream)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading load output: %w", err)
+	}
+
+	return loadError
+}
+
+// BuildImage builds a Docker image from a Dockerfile.
+func (c *Client) BuildImage(ctx context.Context, dockerfilePath, contextPath, tag string, buildArgs map[string]*string) error {
+	buildContext, err := archive.TarWithOptions(contextPath, &archive.TarOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create build context: %w", err)
+	}
+	defer buildContext.Close()
+
+	buildOptions := build.ImageBuildOptions{
+		Tags:       []string{tag},
+		Dockerfile: dockerfilePath,
+		Remove:     true,
+		BuildArgs:  buildArgs,
+	}
+
+	resp, err := c.cli.ImageBuild(ctx, buildContext, buildOptions)
+	if err != nil {
+		return fmt.Errorf("failed to build image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	var buildError error
+	for scanner.Scan() {
+		line := scanner.Text()
+		c.logger.Debug(line)
+
+		var msg struct {
+			Error       string `json:"error"`
+			ErrorDetail struct {
+				Message string `json:"message"`
+			} `json:"errorDetail"`
+		}
+		if err := json.Unmarshal([]byte(line), &msg); err == nil {
+			if msg.Error != "" {
+				buildError = fmt.Errorf("build failed: %s", msg.Error)
+				c.logger.Error("docker build error", "error", msg.Error)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading build output: %w", err)
+	}
+
+	if buildError != nil {
+		return buildError
+	}
+
+	c.logger.With("tag", tag).Info("docker image built successfully")
+	return nil
+}
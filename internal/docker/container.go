@@ -0,0 +1,120 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+// PortBinding publishes a container port on the host.
+type PortBinding struct {
+	// Name identifies the binding in the host port map, see ports.Name.
+	Name      string
+	Host      int
+	Container int
+	Comment   string
+}
+
+// ContainerOptions describes a long-running container managed outside of docker compose.
+type ContainerOptions struct {
+	Name       string
+	Image      string
+	Entrypoint []string
+	Cmd        []string
+	Env        []string
+	Ports      []PortBinding
+	Labels     map[string]string
+	ExtraHosts []string
+}
+
+// StartContainer starts a detached container and returns its ID.
+// An existing container with the same name is reused (and started if stopped), so its data survives re-runs.
+func (c *Client) StartContainer(ctx context.Context, opts ContainerOptions) (string, error) {
+	logger := c.logger.With("container", opts.Name)
+
+	existing, err := c.cli.ContainerInspect(ctx, opts.Name)
+	switch {
+	case err == nil:
+		if existing.State != nil && existing.State.Running {
+			logger.Info("container is already running")
+			return existing.ID, nil
+		}
+		logger.Info("starting existing container")
+		if err := c.cli.ContainerStart(ctx, existing.ID, container.StartOptions{}); err != nil {
+			return "", fmt.Errorf("failed to start container %s: %w", opts.Name, err)
+		}
+		return existing.ID, nil
+	case !errdefs.IsNotFound(err):
+		return "", fmt.Errorf("failed to inspect container %s: %w", opts.Name, err)
+	}
+
+	exists, err := c.ImageExists(ctx, opts.Image)
+	if err != nil {
+		return "", fmt.Errorf("failed to check image %s: %w", opts.Image, err)
+	}
+	if !exists {
+		if err := c.PullImage(ctx, opts.Image); err != nil {
+			return "", err
+		}
+	}
+
+	exposed := make(nat.PortSet, len(opts.Ports))
+	bindings := make(nat.PortMap, len(opts.Ports))
+	for _, p := range opts.Ports {
+		port := nat.Port(fmt.Sprintf("%d/tcp", p.Container))
+		exposed[port] = struct{}{}
+		bindings[port] = append(bindings[port], nat.PortBinding{HostIP: "0.0.0.0", HostPort: strconv.Itoa(p.Host)})
+	}
+
+	resp, err := c.cli.ContainerCreate(ctx, &container.Config{
+		Image:        opts.Image,
+		Entrypoint:   opts.Entrypoint,
+		Cmd:          opts.Cmd,
+		Env:          opts.Env,
+		ExposedPorts: exposed,
+		Labels:       opts.Labels,
+	}, &container.HostConfig{
+		PortBindings:  bindings,
+		ExtraHosts:    opts.ExtraHosts,
+		RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyUnlessStopped},
+	}, nil, nil, opts.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create container %s: %w", opts.Name, err)
+	}
+
+	if err := c.cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return "", fmt.Errorf("failed to start container %s: %w", opts.Name, err)
+	}
+
+	logger.With("id", resp.ID).Info("container started")
+
+	return resp.ID, nil
+}
+
+// RemoveContainer force-removes a container by name. A missing container is not an error.
+func (c *Client) RemoveContainer(ctx context.Context, name string) error {
+	if err := c.cli.ContainerRemove(ctx, name, container.RemoveOptions{Force: true}); err != nil && !errdefs.IsNotFound(err) {
+		return fmt.Errorf("failed to remove container %s: %w", name, err)
+	}
+	return nil
+}
+
+// StopContainer stops a running container by name.
+func (c *Client) StopContainer(ctx context.Context, name string) error {
+	if err := c.cli.ContainerStop(ctx, name, container.StopOptions{}); err != nil {
+		return fmt.Errorf("failed to stop container %s: %w", name, err)
+	}
+	return nil
+}
+
+// RestartContainer restarts a container by name.
+func (c *Client) RestartContainer(ctx context.Context, name string) error {
+	if err := c.cli.ContainerRestart(ctx, name, container.StopOptions{}); err != nil {
+		return fmt.Errorf("failed to restart container %s: %w", name, err)
+	}
+	return nil
+}
@@ -5,8 +5,9 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"os"
+	"strings"
 
+	"github.com/compose-network/local-testnet/internal/logger"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/pkg/stdcopy"
 )
@@ -17,6 +18,7 @@ type RunOptions struct {
 	Cmd        []string
 	Env        []string
 	Volumes    map[string]string // host:container
+	ExtraHosts []string          // host:ip, as in docker run --add-host
 	WorkDir    string
 	User       string
 	AutoRemove bool
@@ -37,6 +39,7 @@ func (c *Client) Run(ctx context.Context, opts RunOptions) (string, error) {
 
 	hostConfig := &container.HostConfig{
 		AutoRemove: opts.AutoRemove,
+		ExtraHosts: opts.ExtraHosts,
 	}
 
 	if len(opts.Volumes) > 0 {
@@ -77,9 +80,12 @@ func (c *Client) Run(ctx context.Context, opts RunOptions) (string, error) {
 		// Start copying output in background
 		go func() {
 			if opts.StreamLogs {
-				// Stream to console and capture
-				outWriter := io.MultiWriter(os.Stdout, &stdout)
-				errWriter := io.MultiWriter(os.Stderr, &stderr)
+				// Stream through the logger and capture
+				logOut, logErr := logger.CommandOutput(componentName(opts.Image))
+				defer logOut.Close()
+				defer logErr.Close()
+				outWriter := io.MultiWriter(logOut, &stdout)
+				errWriter := io.MultiWriter(logErr, &stderr)
 				_, _ = stdcopy.StdCopy(outWriter, errWriter, attachResp.Reader)
 			} else {
 				// Just capture
@@ -116,3 +122,13 @@ func (c *Client) Run(ctx context.Context, opts RunOptions) (string, error) {
 
 	return "", nil
 }
+
+// componentName derives the log component of a container from its image, e.g. "op-deployer" for
+// "us-docker.pkg.dev/oplabs-tools-artifacts/images/op-deployer:v0.4.5".
+func componentName(image string) string {
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
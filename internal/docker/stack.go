@@ -0,0 +1,192 @@
+package docker
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+)
+
+const (
+	// StackLabelKey is the label every localnet container, volume and network carries.
+	StackLabelKey = "stack"
+
+	composeServiceLabelKey = "com.docker.compose.service"
+)
+
+// ComposeProject returns the compose project name of the selected instance. Compose prefixes
+// the names of the L2 volumes with it.
+func ComposeProject() string {
+	return configs.ScopedName("localnet")
+}
+
+// L2Network returns the name of the Docker network shared by the L2 services of the selected instance.
+func L2Network() string {
+	return configs.ScopedName("localnet-l2")
+}
+
+// L2Stack returns the stack label value of all L2 resources of the selected instance.
+func L2Stack() string {
+	return configs.ScopedName("localnet-l2")
+}
+
+// L1Stack returns the stack label value of L1 resources started without Kurtosis.
+func L1Stack() string {
+	return configs.ScopedName("localnet-l1")
+}
+
+// ObservabilityStack returns the stack label value of the observability containers.
+func ObservabilityStack() string {
+	return configs.ScopedName("localnet-observability")
+}
+
+// ContainerStatus is the runtime state of a single stack container.
+type ContainerStatus struct {
+	Service string
+	Name    string
+	Image   string
+	State   string
+	Health  string
+	Status  string
+}
+
+// StackContainers returns the status of every container labeled with the given stack, sorted by service name.
+func (c *Client) StackContainers(ctx context.Context, stack string) ([]ContainerStatus, error) {
+	containers, err := c.cli.ContainerList(ctx, container.ListOptions{All: true, Filters: stackFilter(stack)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	statuses := make([]ContainerStatus, 0, len(containers))
+	for _, ctr := range containers {
+		name := strings.TrimPrefix(firstOr(ctr.Names, ctr.ID), "/")
+		status := ContainerStatus{
+			Service: cmp.Or(ctr.Labels[composeServiceLabelKey], name),
+			Name:    name,
+			Image:   ctr.Image,
+			State:   string(ctr.State),
+			Health:  "-",
+			Status:  ctr.Status,
+		}
+
+		inspect, err := c.cli.ContainerInspect(ctx, ctr.ID)
+		if err == nil && inspect.State != nil && inspect.State.Health != nil {
+			status.Health = string(inspect.State.Health.Status)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	slices.SortFunc(statuses, func(a, b ContainerStatus) int {
+		return cmp.Compare(a.Service, b.Service)
+	})
+
+	return statuses, nil
+}
+
+// PublishedPorts returns the host ports published by the containers of a stack.
+func (c *Client) PublishedPorts(ctx context.Context, stack string) ([]int, error) {
+	containers, err := c.cli.ContainerList(ctx, container.ListOptions{Filters: stackFilter(stack)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var published []int
+	for _, ctr := range containers {
+		for _, port := range ctr.Ports {
+			if port.PublicPort != 0 {
+				published = append(published, int(port.PublicPort))
+			}
+		}
+	}
+	return published, nil
+}
+
+// RemoveStackContainers force-removes every container labeled with the given stack.
+func (c *Client) RemoveStackContainers(ctx context.Context, stack string) (int, error) {
+	containers, err := c.cli.ContainerList(ctx, container.ListOptions{All: true, Filters: stackFilter(stack)})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	for _, ctr := range containers {
+		name := strings.TrimPrefix(firstOr(ctr.Names, ctr.ID), "/")
+		c.logger.With("container", name).Info("removing container")
+		if err := c.cli.ContainerRemove(ctx, ctr.ID, container.RemoveOptions{Force: true}); err != nil && !errdefs.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to remove container %s: %w", name, err)
+		}
+	}
+
+	return len(containers), nil
+}
+
+// RemoveStackVolumes removes every volume labeled with the given stack.
+func (c *Client) RemoveStackVolumes(ctx context.Context, stack string) (int, error) {
+	resp, err := c.cli.VolumeList(ctx, volume.ListOptions{Filters: stackFilter(stack)})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list volumes: %w", err)
+	}
+
+	for _, vol := range resp.Volumes {
+		c.logger.With("volume", vol.Name).Info("removing volume")
+		if err := c.cli.VolumeRemove(ctx, vol.Name, true); err != nil && !errdefs.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to remove volume %s: %w", vol.Name, err)
+		}
+	}
+
+	return len(resp.Volumes), nil
+}
+
+// RemoveStackNetworks removes every network labeled with the given stack.
+func (c *Client) RemoveStackNetworks(ctx context.Context, stack string) (int, error) {
+	networks, err := c.cli.NetworkList(ctx, network.ListOptions{Filters: stackFilter(stack)})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	for _, nw := range networks {
+		c.logger.With("network", nw.Name).Info("removing network")
+		if err := c.cli.NetworkRemove(ctx, nw.ID); err != nil && !errdefs.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to remove network %s: %w", nw.Name, err)
+		}
+	}
+
+	return len(networks), nil
+}
+
+// RemoveImages removes the given images if they exist locally.
+func (c *Client) RemoveImages(ctx context.Context, images ...string) (int, error) {
+	removed := 0
+	for _, img := range images {
+		if _, err := c.cli.ImageRemove(ctx, img, image.RemoveOptions{Force: true, PruneChildren: true}); err != nil {
+			if errdefs.IsNotFound(err) {
+				continue
+			}
+			return removed, fmt.Errorf("failed to remove image %s: %w", img, err)
+		}
+		c.logger.With("image", img).Info("image removed")
+		removed++
+	}
+
+	return removed, nil
+}
+
+func stackFilter(stack string) filters.Args {
+	return filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", StackLabelKey, stack)))
+}
+
+func firstOr(values []string, fallback string) string {
+	if len(values) == 0 {
+		return fallback
+	}
+	return values[0]
+}
@@ -0,0 +1,160 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/compose-network/local-testnet/configs"
+)
+
+const (
+	versionTimeout = 10 * time.Second
+
+	// minComposeVersion is the oldest Docker Compose release known to handle the rendered compose files.
+	minComposeVersion = "2.20.0"
+
+	kurtosisSDKModule = "github.com/kurtosis-tech/kurtosis/api/golang"
+)
+
+// binary is an external tool localnet shells out to.
+type binary struct {
+	name        string
+	versionArgs []string
+	// neededFor explains which commands use the binary. Empty means the binary is not needed
+	// with the current configuration, so a missing one is skipped instead of failed.
+	neededFor string
+	fix       string
+}
+
+func binaries(cfg configs.Config) []binary {
+	kurtosisNeededFor := ""
+	if cfg.L1.BackendOrDefault() == configs.L1BackendKurtosis {
+		kurtosisNeededFor = "the kurtosis L1 backend"
+	}
+
+	return []binary{
+		{"git", []string{"--version"}, "cloning L2 repositories", "install git: https://git-scm.com/downloads"},
+		{"forge", []string{"--version"}, "compiling L2 contracts", "install Foundry: curl -L https://foundry.paradigm.xyz | bash && foundryup"},
+		{"just", []string{"--version"}, "deploying the dispute contracts", "install just: https://github.com/casey/just#installation"},
+		{"jq", []string{"--version"}, "deploying the dispute contracts", "install jq: https://jqlang.org/download/"},
+		{"kurtosis", []string{"version"}, kurtosisNeededFor, "install the Kurtosis CLI: https://docs.kurtosis.com/install"},
+	}
+}
+
+// checkBinaries checks that every needed tool is on PATH and reports its version.
+func checkBinaries(ctx context.Context, cfg configs.Config) []Result {
+	results := []Result{checkDockerCLI(ctx), checkCompose(ctx)}
+
+	for _, bin := range binaries(cfg) {
+		if _, err := exec.LookPath(bin.name); err != nil {
+			if bin.neededFor == "" {
+				results = append(results, skip(bin.name, "not installed, not needed with the current configuration"))
+				continue
+			}
+			results = append(results, fail(bin.name, "not found on PATH, needed for "+bin.neededFor, bin.fix))
+			continue
+		}
+
+		version, err := commandOutput(ctx, bin.name, bin.versionArgs...)
+		if err != nil {
+			results = append(results, warn(bin.name, fmt.Sprintf("installed, but '%s %s' failed: %v", bin.name, strings.Join(bin.versionArgs, " "), err), bin.fix))
+			continue
+		}
+
+		if bin.name == "kurtosis" {
+			results = append(results, checkKurtosisVersion(version))
+			continue
+		}
+		results = append(results, ok(bin.name, version))
+	}
+
+	return results
+}
+
+func checkDockerCLI(ctx context.Context) Result {
+	const fix = "install Docker: https://docs.docker.com/get-docker/"
+	if _, err := exec.LookPath("docker"); err != nil {
+		return fail("docker", "not found on PATH", fix)
+	}
+	version, err := commandOutput(ctx, "docker", "--version")
+	if err != nil {
+		return fail("docker", fmt.Sprintf("'docker --version' failed: %v", err), fix)
+	}
+	return ok("docker", version)
+}
+
+func checkCompose(ctx context.Context) Result {
+	const check = "docker compose"
+	fix := fmt.Sprintf("install or update the Docker Compose plugin to v%s or later: https://docs.docker.com/compose/install/", minComposeVersion)
+
+	version, err := commandOutput(ctx, "docker", "compose", "version", "--short")
+	if err != nil {
+		return fail(check, fmt.Sprintf("'docker compose version' failed: %v", err), fix)
+	}
+	if !versionAtLeast(parseVersion(version), parseVersion(minComposeVersion)) {
+		return fail(check, fmt.Sprintf("%s is older than %s", version, minComposeVersion), fix)
+	}
+	return ok(check, version)
+}
+
+// checkKurtosisVersion compares the CLI with the Kurtosis SDK localnet is built with. The CLI starts
+// an engine of its own version, and engine API calls from a different SDK version may be rejected.
+func checkKurtosisVersion(output string) Result {
+	cliVersion := parseVersion(output)
+	sdkVersion := kurtosisSDKVersion()
+	detail := "CLI " + versionString(cliVersion)
+	if sdkVersion == nil || cliVersion == nil {
+		return ok("kurtosis", detail)
+	}
+
+	if cliVersion[0] != sdkVersion[0] || cliVersion[1] != sdkVersion[1] {
+		return warn("kurtosis",
+			fmt.Sprintf("%s, localnet is built with SDK %s", detail, versionString(sdkVersion)),
+			fmt.Sprintf("install Kurtosis %s and restart the engine: kurtosis engine restart", versionString(sdkVersion)))
+	}
+	return ok("kurtosis", fmt.Sprintf("%s (SDK %s)", detail, versionString(sdkVersion)))
+}
+
+func kurtosisSDKVersion() []int {
+	info, found := debug.ReadBuildInfo()
+	if !found {
+		return nil
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == kurtosisSDKModule {
+			return parseVersion(dep.Version)
+		}
+	}
+	return nil
+}
+
+func versionString(version []int) string {
+	if version == nil {
+		return "unknown"
+	}
+	parts := make([]string, len(version))
+	for i, n := range version {
+		parts[i] = fmt.Sprint(n)
+	}
+	return strings.Join(parts, ".")
+}
+
+// commandOutput runs a command and returns the first line of its output.
+func commandOutput(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, versionTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	if err != nil {
+		if line != "" {
+			return "", fmt.Errorf("%w: %s", err, line)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
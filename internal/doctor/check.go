@@ -0,0 +1,102 @@
+package doctor
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Status is the outcome of a single check.
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "FAIL"
+	// StatusSkip marks checks that do not apply to the current configuration.
+	StatusSkip Status = "skip"
+)
+
+// Result is the outcome of a single check. Fix tells how to resolve a warning or failure.
+type Result struct {
+	Check  string
+	Status Status
+	Detail string
+	Fix    string
+}
+
+func ok(check, detail string) Result {
+	return Result{Check: check, Status: StatusOK, Detail: detail}
+}
+
+func warn(check, detail, fix string) Result {
+	return Result{Check: check, Status: StatusWarn, Detail: detail, Fix: fix}
+}
+
+func fail(check, detail, fix string) Result {
+	return Result{Check: check, Status: StatusFail, Detail: detail, Fix: fix}
+}
+
+func skip(check, detail string) Result {
+	return Result{Check: check, Status: StatusSkip, Detail: detail}
+}
+
+// render prints the results as a table followed by the fixes of every warning and failure.
+func render(out io.Writer, results []Result) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tDETAIL")
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Check, result.Status, result.Detail)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	var fixes []string
+	for _, result := range results {
+		if result.Fix != "" {
+			fixes = append(fixes, fmt.Sprintf("  %s: %s", result.Check, result.Fix))
+		}
+	}
+	if len(fixes) > 0 {
+		fmt.Fprintf(out, "\nFixes:\n%s\n", strings.Join(fixes, "\n"))
+	}
+
+	return nil
+}
+
+var versionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+// parseVersion extracts the first dotted version number of s, e.g. [2 29 1] from "Docker Compose version v2.29.1".
+func parseVersion(s string) []int {
+	match := versionPattern.FindString(s)
+	if match == "" {
+		return nil
+	}
+	var version []int
+	for _, part := range strings.Split(match, ".") {
+		n, _ := strconv.Atoi(part)
+		version = append(version, n)
+	}
+	return version
+}
+
+// versionAtLeast reports whether version is at least minimum, comparing missing parts as 0.
+func versionAtLeast(version, minimum []int) bool {
+	for i := range max(len(version), len(minimum)) {
+		var v, m int
+		if i < len(version) {
+			v = version[i]
+		}
+		if i < len(minimum) {
+			m = minimum[i]
+		}
+		if v != m {
+			return v > m
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/docker"
+	"github.com/spf13/cobra"
+)
+
+var CMD = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check prerequisites and the environment before starting a localnet",
+	Long: `Checks the tools localnet shells out to (docker, docker compose, git, forge, just, jq, kurtosis)
+and their versions, access to the Docker API, free disk space, the host ports of the configured services
+and, when running in a container, the Docker-in-Docker setup (HOST_PROJECT_PATH).
+
+Every warning and failure comes with a fix. Exits with an error when a check fails.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		cfg := configs.Values
+
+		rootDir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %w", err)
+		}
+
+		results := checkBinaries(ctx, cfg)
+
+		// The port check falls back to plain availability when the daemon is unreachable.
+		var client *docker.Client
+		var dockerRootDir string
+		if c, err := docker.New(); err != nil {
+			results = append(results, fail("docker daemon", err.Error(), daemonFix(err)))
+		} else {
+			defer c.Close()
+			var daemon Result
+			daemon, dockerRootDir = checkDaemon(ctx, c)
+			results = append(results, daemon)
+			if daemon.Status != StatusFail {
+				client = c
+			}
+		}
+
+		results = append(results, checkDiskSpace(rootDir, dockerRootDir)...)
+		results = append(results, checkDockerInDocker(rootDir, cfg)...)
+		results = append(results, checkPorts(ctx, client, cfg)...)
+
+		if err := render(cmd.OutOrStdout(), results); err != nil {
+			return err
+		}
+
+		var failed int
+		for _, result := range results {
+			if result.Status == StatusFail {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d checks failed", failed, len(results))
+		}
+		return nil
+	},
+}
@@ -0,0 +1,42 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/compose-network/local-testnet/internal/docker"
+)
+
+// checkDaemon checks the Docker API is reachable and returns the daemon's data root, empty when unknown.
+func checkDaemon(ctx context.Context, client *docker.Client) (Result, string) {
+	const check = "docker daemon"
+
+	version, err := client.ServerVersion(ctx)
+	if err != nil {
+		return fail(check, err.Error(), daemonFix(err)), ""
+	}
+
+	detail := fmt.Sprintf("Docker %s, API %s (%s/%s)", version.Version, version.APIVersion, version.Os, version.Arch)
+	info, err := client.Info(ctx)
+	if err != nil {
+		return ok(check, detail), ""
+	}
+	return ok(check, detail), info.DockerRootDir
+}
+
+func daemonFix(err error) string {
+	switch {
+	case errors.Is(err, fs.ErrPermission) || strings.Contains(err.Error(), "permission denied"):
+		return "add your user to the docker group (sudo usermod -aG docker $USER) and log in again"
+	case os.Getenv(hostProjectPathEnv) != "":
+		return "mount the Docker socket into the container: -v /var/run/docker.sock:/var/run/docker.sock"
+	case os.Getenv("DOCKER_HOST") != "":
+		return "DOCKER_HOST is set to " + os.Getenv("DOCKER_HOST") + ", check that the daemon listens there"
+	default:
+		return "start the Docker daemon (or Docker Desktop)"
+	}
+}
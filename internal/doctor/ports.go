@@ -0,0 +1,61 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/compose-network/local-testnet/configs"
+	dockerclient "github.com/compose-network/local-testnet/internal/docker"
+	"github.com/compose-network/local-testnet/internal/l1"
+	"github.com/compose-network/local-testnet/internal/l2/blockscout"
+	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
+	"github.com/compose-network/local-testnet/internal/observability"
+	"github.com/compose-network/local-testnet/internal/ports"
+)
+
+// checkPorts checks the default host ports of every configured service. Ports published by the
+// selected instance's own containers are counted as free, as they are on deployment.
+func checkPorts(ctx context.Context, client *dockerclient.Client, cfg configs.Config) []Result {
+	requested := l1.HostPorts(cfg.L1)
+	requested = append(requested, docker.HostPorts(cfg.L2)...)
+	if cfg.L2.Blockscout.Enabled {
+		requested = append(requested, blockscout.HostPorts(cfg.L2)...)
+	}
+	requested = append(requested, observability.HostPorts()...)
+
+	var owned []int
+	if client != nil {
+		for _, stack := range []string{dockerclient.L1Stack(), dockerclient.L2Stack(), dockerclient.ObservabilityStack()} {
+			published, err := client.PublishedPorts(ctx, stack)
+			if err != nil {
+				continue
+			}
+			owned = append(owned, published...)
+		}
+	}
+
+	var taken []string
+	var results []Result
+	for _, port := range requested {
+		if slices.Contains(owned, port.Default) || ports.Available(port.Default) {
+			continue
+		}
+		taken = append(taken, fmt.Sprintf("%d (%s)", port.Default, port.Name))
+
+		check := fmt.Sprintf("port %d", port.Default)
+		detail := fmt.Sprintf("in use, needed by %s", port.Name)
+		if cfg.Ports.Mode == ports.ModeAuto {
+			results = append(results, warn(check, detail+", will be reassigned (auto port mode)", ""))
+		} else {
+			results = append(results, fail(check, detail, "stop the process listening on it, change the port in the config or use --port-mode auto"))
+		}
+	}
+
+	if len(taken) == 0 {
+		return []Result{ok("host ports", fmt.Sprintf("%d ports free", len(requested)))}
+	}
+	summary := fmt.Sprintf("%d of %d ports in use: %s", len(taken), len(requested), strings.Join(taken, ", "))
+	return append([]Result{warn("host ports", summary, "")}, results...)
+}
@@ -0,0 +1,115 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/compose-network/local-testnet/configs"
+)
+
+const (
+	gib = 1 << 30
+
+	// A deployment clones op-geth, builds several images and keeps the chain data of every rollup.
+	diskWarnBytes = 30 * gib
+	diskFailBytes = 10 * gib
+
+	// hostProjectPathEnv and workspaceDir describe the Docker-in-Docker setup: localnet runs in a container
+	// with the project mounted at /workspace and the host's Docker socket, see build/DOCKER.md.
+	hostProjectPathEnv = "HOST_PROJECT_PATH"
+	workspaceDir       = "/workspace"
+)
+
+// checkDisk checks the free space of the filesystem holding path.
+func checkDisk(check, path string) Result {
+	free, err := freeBytes(path)
+	if err != nil {
+		return warn(check, fmt.Sprintf("cannot read free space of %s: %v", path, err), "")
+	}
+
+	detail := fmt.Sprintf("%.1f GiB free on %s", float64(free)/gib, path)
+	fix := fmt.Sprintf("free up space on %s, e.g. with docker system prune or localnet l2 clean --all", path)
+	switch {
+	case free < diskFailBytes:
+		return fail(check, fmt.Sprintf("%s, at least %d GiB needed", detail, diskFailBytes/gib), fix)
+	case free < diskWarnBytes:
+		return warn(check, fmt.Sprintf("%s, %d GiB recommended", detail, diskWarnBytes/gib), fix)
+	default:
+		return ok(check, detail)
+	}
+}
+
+// checkDiskSpace checks the work directory and, when it is visible from here, the Docker data root.
+func checkDiskSpace(rootDir, dockerRootDir string) []Result {
+	results := []Result{checkDisk("disk (work dir)", rootDir)}
+	if dockerRootDir != "" {
+		if _, err := os.Stat(dockerRootDir); err == nil {
+			results = append(results, checkDisk("disk (docker)", dockerRootDir))
+		}
+	}
+	return results
+}
+
+// checkDockerInDocker detects whether localnet runs inside a container and checks the setup needed for
+// nested containers to mount project paths: bind mounts are resolved on the host, so container paths
+// under /workspace are translated using HOST_PROJECT_PATH.
+func checkDockerInDocker(rootDir string, cfg configs.Config) []Result {
+	const check = "docker-in-docker"
+	runFix := fmt.Sprintf("run with -v $(pwd):%s -w %s -e %s=$(pwd), see build/DOCKER.md", workspaceDir, workspaceDir, hostProjectPathEnv)
+
+	hostProjectPath := os.Getenv(hostProjectPathEnv)
+	if hostProjectPath == "" {
+		if inContainer() {
+			return []Result{warn(check, fmt.Sprintf("running in a container without %s, nested containers will mount container paths", hostProjectPathEnv), runFix)}
+		}
+		return []Result{ok(check, "not running in a container")}
+	}
+
+	if !filepath.IsAbs(hostProjectPath) {
+		return []Result{fail(check, fmt.Sprintf("%s=%s is not an absolute path", hostProjectPathEnv, hostProjectPath), runFix)}
+	}
+	if !underWorkspace(rootDir) {
+		return []Result{fail(check, fmt.Sprintf("%s is set but the working directory %s is outside %s", hostProjectPathEnv, rootDir, workspaceDir), runFix)}
+	}
+
+	results := []Result{ok(check, fmt.Sprintf("%s mounted from %s", workspaceDir, hostProjectPath))}
+
+	// Local repositories outside the workspace cannot be translated to host paths.
+	repositories := cfg.L2.SourceRepositories()
+	for _, name := range configs.RepositoryNames {
+		repo, exists := repositories[name]
+		if !exists || repo.LocalPath == "" || repo.URL != "" {
+			continue
+		}
+		path := repo.LocalPath
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(rootDir, path)
+		}
+		if !underWorkspace(path) {
+			results = append(results, fail("repository "+string(name),
+				fmt.Sprintf("local-path %s is outside %s and cannot be mounted by nested containers", repo.LocalPath, workspaceDir),
+				fmt.Sprintf("move the checkout into the project or set l2.repositories.%s.url instead", name)))
+		}
+	}
+
+	return results
+}
+
+func underWorkspace(path string) bool {
+	path = filepath.Clean(path)
+	return path == workspaceDir || strings.HasPrefix(path, workspaceDir+string(filepath.Separator))
+}
+
+// inContainer reports whether this process runs inside a Docker container.
+func inContainer() bool {
+	if _, err := os.Stat("/.dockerenv"); err == nil {
+		return true
+	}
+	cgroup, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(cgroup), "docker") || strings.Contains(string(cgroup), "containerd")
+}
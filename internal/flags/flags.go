@@ -0,0 +1,45 @@
+// Package flags declares command-line flags bound to viper configuration keys.
+package flags
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Def defines a command-line flag with its configuration.
+type (
+	Type interface {
+		string | int | bool
+	}
+
+	Def[T Type] struct {
+		Name        string
+		ViperKey    string
+		Default     T
+		Description string
+	}
+)
+
+// Declare declares multiple flags on fs and binds them to viper configuration keys.
+func Declare[T Type](fs *pflag.FlagSet, defs []Def[T]) error {
+	for _, def := range defs {
+		if err := declare(fs, def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// declare declares a single flag and binds it to a viper configuration key.
+// The type parameter T determines the flag type (string, int, or bool).
+func declare[T Type](fs *pflag.FlagSet, def Def[T]) error {
+	switch value := any(def.Default).(type) {
+	case string:
+		fs.String(def.Name, value, def.Description)
+	case int:
+		fs.Int(def.Name, value, def.Description)
+	case bool:
+		fs.Bool(def.Name, value, def.Description)
+	}
+	return viper.BindPFlag(def.ViperKey, fs.Lookup(def.Name))
+}
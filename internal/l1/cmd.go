@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/compose-network/local-testnet/configs"
 	"github.com/spf13/cobra"
 )
 
@@ -12,7 +13,7 @@ var CMD = &cobra.Command{
 	Short: "Commands for running L1 network",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		slog.Info("starting l1")
-		err := start(cmd.Context())
+		_, err := Start(cmd.Context(), configs.Values.L1, StartOptions{EndpointHost: DefaultEndpointHost, Locked: configs.Values.Locked, PortMode: configs.Values.Ports.Mode})
 		if err != nil {
 			return fmt.Errorf("error occurred starting l1: %w", err)
 		}
@@ -0,0 +1,324 @@
+package l1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/docker"
+	"github.com/compose-network/local-testnet/internal/ports"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	_ "embed"
+)
+
+//go:embed beacon.conf.tmpl
+var beaconConfTemplate string
+
+const (
+	devGethImage       = "ethereum/client-go:v1.15.10"
+	devBeaconImage     = "nginx:alpine"
+	devGethContainer   = "localnet-l1-geth"
+	devBeaconContainer = "localnet-l1-beacon"
+
+	devELRPCPort  = 8545
+	devELWSPort   = 8546
+	devBeaconPort = 5052
+
+	// devBlockTime is the dev-mode block period, also reported as the beacon slot duration.
+	devBlockTime = 2
+)
+
+// devAccount is the well-known first account of the "test test ... junk" mnemonic.
+// The dev backend funds it from the geth developer account and hands it out as prefunded account.
+var devAccount = PrefundedAccount{
+	Address:    "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
+	PrivateKey: "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80",
+}
+
+// devFundingAmount is the balance every funded account is topped up to (10000 ETH).
+var devFundingAmount = new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18))
+
+// ResolveHostPorts checks (fixed mode) or assigns (auto mode) the host ports published by the L1 backend and
+// records them in the port map file of the instance. Kurtosis assigns its ports itself, so only the dev
+// backend has any. Ports published by the running dev L1 containers belong to a previous run and count as free.
+func ResolveHostPorts(ctx context.Context, cfg configs.L1, mode ports.Mode) (ports.Map, error) {
+	requested := HostPorts(cfg)
+	if len(requested) == 0 {
+		return nil, nil
+	}
+
+	path := filepath.Join(configs.WorkDir(), ports.FileName)
+	previous, err := ports.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := docker.New()
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to create docker client"))
+	}
+	defer client.Close()
+
+	owned, err := client.PublishedPorts(ctx, docker.L1Stack())
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := ports.Resolve(requested, mode, previous, owned)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("dev L1 host ports are not available"))
+	}
+
+	for _, port := range requested {
+		if resolved[port.Name] != port.Default {
+			slog.With("port", port.Name, "default", port.Default, "host_port", resolved[port.Name]).Info("host port reassigned")
+		}
+	}
+
+	if err := ports.Record(path, resolved); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// HostPorts lists the host ports published by the L1 backend with their defaults, none for Kurtosis.
+func HostPorts(cfg configs.L1) []ports.Port {
+	if cfg.BackendOrDefault() != configs.L1BackendDev {
+		return nil
+	}
+	return []ports.Port{
+		{Name: ports.Name(devGethContainer, devELRPCPort), Default: devELRPCPort},
+		{Name: ports.Name(devGethContainer, devELWSPort), Default: devELWSPort},
+		{Name: ports.Name(devBeaconContainer, devBeaconPort), Default: devBeaconPort},
+	}
+}
+
+// startDev runs a single dev-mode geth container plus a mock beacon API served by nginx, published on
+// the host ports resolved for the instance.
+func startDev(ctx context.Context, cfg configs.L1, opts StartOptions) (Output, error) {
+	hostPorts, err := ResolveHostPorts(ctx, cfg, opts.PortMode)
+	if err != nil {
+		return Output{}, err
+	}
+	var (
+		rpcPort    = hostPorts.Get(ports.Name(devGethContainer, devELRPCPort), devELRPCPort)
+		wsPort     = hostPorts.Get(ports.Name(devGethContainer, devELWSPort), devELWSPort)
+		beaconPort = hostPorts.Get(ports.Name(devBeaconContainer, devBeaconPort), devBeaconPort)
+	)
+
+	client, err := docker.New()
+	if err != nil {
+		return Output{}, errors.Join(err, errors.New("failed to create docker client"))
+	}
+	defer client.Close()
+
+	labels := map[string]string{docker.StackLabelKey: docker.L1Stack()}
+
+	slog.Info("starting dev geth")
+	_, err = client.StartContainer(ctx, docker.ContainerOptions{
+		Name:  configs.ScopedName(devGethContainer),
+		Image: devGethImage,
+		Cmd: []string{
+			"--dev",
+			fmt.Sprintf("--dev.period=%d", devBlockTime),
+			"--datadir=/data",
+			"--http", "--http.addr=0.0.0.0", fmt.Sprintf("--http.port=%d", devELRPCPort),
+			"--http.api=eth,net,web3,debug,txpool", "--http.vhosts=*", "--http.corsdomain=*",
+			"--ws", "--ws.addr=0.0.0.0", fmt.Sprintf("--ws.port=%d", devELWSPort),
+			"--ws.api=eth,net,web3,debug,txpool", "--ws.origins=*",
+		},
+		Ports: []docker.PortBinding{
+			{Host: rpcPort, Container: devELRPCPort},
+			{Host: wsPort, Container: devELWSPort},
+		},
+		Labels: labels,
+	})
+	if err != nil {
+		return Output{}, errors.Join(err, errors.New("failed to start dev geth"))
+	}
+
+	localURL := fmt.Sprintf("http://%s:%d", localHost(), rpcPort)
+	rpcClient, err := waitForDevRPC(ctx, localURL)
+	if err != nil {
+		return Output{}, err
+	}
+	defer rpcClient.Close()
+	ethClient := ethclient.NewClient(rpcClient)
+
+	chainID, err := ethClient.ChainID(ctx)
+	if err != nil {
+		return Output{}, errors.Join(err, errors.New("failed to get dev chain ID"))
+	}
+
+	genesis, err := ethClient.HeaderByNumber(ctx, big.NewInt(0))
+	if err != nil {
+		return Output{}, errors.Join(err, errors.New("failed to get dev genesis block"))
+	}
+
+	beaconConf, err := renderBeaconConf(genesis.Time)
+	if err != nil {
+		return Output{}, err
+	}
+
+	// The beacon is stateless and bound to the genesis time of the current geth container, so it is always recreated.
+	if err := client.RemoveContainer(ctx, configs.ScopedName(devBeaconContainer)); err != nil {
+		return Output{}, err
+	}
+
+	slog.Info("starting mock beacon API")
+	_, err = client.StartContainer(ctx, docker.ContainerOptions{
+		Name:       configs.ScopedName(devBeaconContainer),
+		Image:      devBeaconImage,
+		Entrypoint: []string{"sh", "-c"},
+		Cmd:        []string{`printf '%s' "$BEACON_CONF" > /etc/nginx/conf.d/default.conf && exec nginx -g 'daemon off;'`},
+		Env:        []string{"BEACON_CONF=" + beaconConf},
+		Ports:      []docker.PortBinding{{Host: beaconPort, Container: devBeaconPort}},
+		Labels:     labels,
+	})
+	if err != nil {
+		return Output{}, errors.Join(err, errors.New("failed to start mock beacon"))
+	}
+
+	recipients := append([]string{devAccount.Address}, opts.Fund...)
+	if err := fundDevAccounts(ctx, rpcClient, ethClient, recipients); err != nil {
+		return Output{}, err
+	}
+
+	output := Output{
+		ChainID:           int(chainID.Int64()),
+		ELRPCURL:          fmt.Sprintf("http://%s:%d", opts.EndpointHost, rpcPort),
+		CLBeaconURL:       fmt.Sprintf("http://%s:%d", opts.EndpointHost, beaconPort),
+		PrefundedAccounts: []PrefundedAccount{devAccount},
+	}
+
+	slog.Info("dev L1 network is ready",
+		slog.Int("chain_id", output.ChainID),
+		slog.String("el_url", output.ELRPCURL),
+		slog.String("cl_url", output.CLBeaconURL))
+
+	return output, nil
+}
+
+func renderBeaconConf(genesisTime uint64) (string, error) {
+	tmpl, err := template.New("beacon").Parse(beaconConfTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse beacon config template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, struct {
+		Port           int
+		SecondsPerSlot int
+		GenesisTime    uint64
+	}{
+		Port:           devBeaconPort,
+		SecondsPerSlot: devBlockTime,
+		GenesisTime:    genesisTime,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render beacon config: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// fundDevAccounts tops up the given addresses from the unlocked geth developer account.
+func fundDevAccounts(ctx context.Context, rpcClient *rpc.Client, ethClient *ethclient.Client, addresses []string) error {
+	var accounts []common.Address
+	if err := rpcClient.CallContext(ctx, &accounts, "eth_accounts"); err != nil {
+		return errors.Join(err, errors.New("failed to list dev accounts"))
+	}
+	if len(accounts) == 0 {
+		return errors.New("dev geth has no developer account")
+	}
+	developer := accounts[0]
+
+	for _, address := range addresses {
+		if !common.IsHexAddress(address) {
+			return fmt.Errorf("invalid address to fund: '%s'", address)
+		}
+		to := common.HexToAddress(address)
+
+		balance, err := ethClient.BalanceAt(ctx, to, nil)
+		if err != nil {
+			return errors.Join(err, fmt.Errorf("failed to get balance of %s", to))
+		}
+		if balance.Cmp(devFundingAmount) >= 0 {
+			continue
+		}
+
+		var txHash common.Hash
+		err = rpcClient.CallContext(ctx, &txHash, "eth_sendTransaction", map[string]any{
+			"from":  developer,
+			"to":    to,
+			"value": (*hexutil.Big)(new(big.Int).Sub(devFundingAmount, balance)),
+		})
+		if err != nil {
+			return errors.Join(err, fmt.Errorf("failed to fund %s", to))
+		}
+
+		if err := waitForReceipt(ctx, ethClient, txHash); err != nil {
+			return err
+		}
+		slog.With("address", to.Hex()).Info("dev account funded")
+	}
+
+	return nil
+}
+
+func waitForDevRPC(ctx context.Context, url string) (*rpc.Client, error) {
+	for range 60 {
+		client, err := rpc.DialContext(ctx, url)
+		if err == nil {
+			var blockNumber hexutil.Uint64
+			if err := client.CallContext(ctx, &blockNumber, "eth_blockNumber"); err == nil {
+				return client, nil
+			}
+			client.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+
+	return nil, fmt.Errorf("timed out waiting for dev geth RPC at %s", url)
+}
+
+func waitForReceipt(ctx context.Context, client *ethclient.Client, txHash common.Hash) error {
+	for range 30 {
+		if _, err := client.TransactionReceipt(ctx, txHash); err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+
+	return fmt.Errorf("timed out waiting for receipt of %s", txHash)
+}
+
+// localHost is how this process reaches ports published on the Docker host.
+func localHost() string {
+	if os.Getenv("HOST_PROJECT_PATH") != "" {
+		return "host.docker.internal"
+	}
+	return "localhost"
+}
@@ -0,0 +1,40 @@
+package l1
+
+import (
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/flags"
+)
+
+var (
+	stringFlags = []flags.Def[string]{
+		{Name: "backend", ViperKey: "l1.backend", Default: string(configs.L1BackendKurtosis), Description: "L1 backend (kurtosis or dev)"},
+
+		// Kurtosis
+		{Name: "package-name", ViperKey: "l1.kurtosis.package-name", Default: DefaultPackageName, Description: "Kurtosis package to run"},
+		{Name: "enclave-name", ViperKey: "l1.kurtosis.enclave-name", Default: DefaultEnclaveName, Description: "Kurtosis enclave name (use distinct names to run several L1s)"},
+		{Name: "params-file", ViperKey: "l1.kurtosis.params-file", Description: "YAML file merged onto the embedded package params"},
+		{Name: "el-image", ViperKey: "l1.kurtosis.el-image", Description: "Execution client image of every participant"},
+		{Name: "cl-image", ViperKey: "l1.kurtosis.cl-image", Description: "Consensus client image of every participant"},
+	}
+
+	intFlags = []flags.Def[int]{
+		{Name: "participant-count", ViperKey: "l1.kurtosis.participant-count", Description: "Number of EL/CL pairs per participant (0 keeps the default)"},
+		{Name: "validator-count", ViperKey: "l1.kurtosis.validator-count", Description: "Validators per EL/CL pair (0 keeps the default)"},
+		{Name: "ssv-node-count", ViperKey: "l1.kurtosis.ssv-node-count", Description: "Number of SSV nodes (0 keeps the default)"},
+		{Name: "anchor-node-count", ViperKey: "l1.kurtosis.anchor-node-count", Description: "Number of Anchor nodes (0 keeps the default)"},
+	}
+)
+
+func init() {
+	// Flags are persistent so the lifecycle subcommands target the same enclave and backend.
+	if err := flags.Declare(CMD.PersistentFlags(), stringFlags); err != nil {
+		panic(err)
+	}
+	if err := flags.Declare(CMD.PersistentFlags(), intFlags); err != nil {
+		panic(err)
+	}
+	CMD.AddCommand(stopCmd)
+	CMD.AddCommand(statusCmd)
+	CMD.AddCommand(destroyCmd)
+	CMD.AddCommand(restartServiceCmd)
+}
@@ -0,0 +1,369 @@
+package l1
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/docker"
+	"github.com/compose-network/local-testnet/internal/ports"
+	"github.com/kurtosis-tech/kurtosis/api/golang/core/lib/enclaves"
+	config "github.com/kurtosis-tech/kurtosis/api/golang/core/lib/starlark_run_config"
+	"github.com/kurtosis-tech/kurtosis/api/golang/engine/kurtosis_engine_rpc_api_bindings"
+	"github.com/kurtosis-tech/kurtosis/api/golang/engine/lib/kurtosis_context"
+	"github.com/spf13/cobra"
+)
+
+// restartServiceScript restarts a single enclave service in place, keeping its config and ports.
+const restartServiceScript = `
+def run(plan, args):
+    plan.stop_service(name = args["name"])
+    plan.start_service(name = args["name"])
+`
+
+type (
+	// Status is the machine-readable state of the L1 network printed by `l1 status`.
+	Status struct {
+		Backend  configs.L1Backend `json:"backend"`
+		Enclave  string            `json:"enclave,omitempty"`
+		State    string            `json:"state"`
+		Services []ServiceStatus   `json:"services"`
+	}
+
+	ServiceStatus struct {
+		Name  string       `json:"name"`
+		State string       `json:"state,omitempty"`
+		Ports []PortStatus `json:"ports"`
+	}
+
+	PortStatus struct {
+		ID          string `json:"id"`
+		PrivatePort int    `json:"private_port"`
+		PublicPort  int    `json:"public_port,omitempty"`
+		URL         string `json:"url,omitempty"`
+	}
+)
+
+var stopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the L1 network, keeping its data",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := configs.Values.L1
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		if cfg.BackendOrDefault() == configs.L1BackendDev {
+			return withDockerClient(func(client *docker.Client) error {
+				for _, name := range []string{configs.ScopedName(devBeaconContainer), configs.ScopedName(devGethContainer)} {
+					if err := client.StopContainer(ctx, name); err != nil {
+						return err
+					}
+				}
+				slog.Info("dev L1 stopped")
+				return nil
+			})
+		}
+
+		kurtosisCtx, err := kurtosis_context.NewKurtosisContextFromLocalEngine()
+		if err != nil {
+			return errors.Join(err, errors.New("failed to create kurtosis context"))
+		}
+		if err := kurtosisCtx.StopEnclave(ctx, cfg.Kurtosis.EnclaveName); err != nil {
+			return errors.Join(err, fmt.Errorf("failed to stop enclave %s", cfg.Kurtosis.EnclaveName))
+		}
+
+		slog.With("enclave", cfg.Kurtosis.EnclaveName).Info("L1 enclave stopped")
+		return nil
+	},
+}
+
+var destroyCmd = &cobra.Command{
+	Use:   "destroy",
+	Short: "Remove the L1 network and all of its data",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := configs.Values.L1
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		if cfg.BackendOrDefault() == configs.L1BackendDev {
+			return withDockerClient(func(client *docker.Client) error {
+				count, err := client.RemoveStackContainers(ctx, docker.L1Stack())
+				if err != nil {
+					return err
+				}
+				slog.With("count", count).Info("dev L1 containers removed")
+				return nil
+			})
+		}
+
+		kurtosisCtx, err := kurtosis_context.NewKurtosisContextFromLocalEngine()
+		if err != nil {
+			return errors.Join(err, errors.New("failed to create kurtosis context"))
+		}
+		if err := kurtosisCtx.DestroyEnclave(ctx, cfg.Kurtosis.EnclaveName); err != nil {
+			return errors.Join(err, fmt.Errorf("failed to destroy enclave %s", cfg.Kurtosis.EnclaveName))
+		}
+
+		slog.With("enclave", cfg.Kurtosis.EnclaveName).Info("L1 enclave destroyed")
+		return nil
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the L1 state and service endpoints as JSON",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := configs.Values.L1
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+
+		var (
+			status Status
+			err    error
+		)
+		if cfg.BackendOrDefault() == configs.L1BackendDev {
+			status, err = devStatus(cmd.Context())
+		} else {
+			status, err = kurtosisStatus(cmd.Context(), cfg.Kurtosis.EnclaveName)
+		}
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(status)
+	},
+}
+
+var restartServiceCmd = &cobra.Command{
+	Use:   "restart-service <name>...",
+	Short: "Restart one or more L1 services (e.g. ssv-node-0)",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := configs.Values.L1
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		if cfg.BackendOrDefault() == configs.L1BackendDev {
+			return withDockerClient(func(client *docker.Client) error {
+				containers, err := devContainers(ctx, client, args)
+				if err != nil {
+					return err
+				}
+				for _, name := range containers {
+					if err := client.RestartContainer(ctx, name); err != nil {
+						return err
+					}
+					slog.With("service", name).Info("L1 service restarted")
+				}
+				return nil
+			})
+		}
+
+		kurtosisCtx, err := kurtosis_context.NewKurtosisContextFromLocalEngine()
+		if err != nil {
+			return errors.Join(err, errors.New("failed to create kurtosis context"))
+		}
+		enclaveCtx, err := kurtosisCtx.GetEnclaveContext(ctx, cfg.Kurtosis.EnclaveName)
+		if err != nil {
+			return errors.Join(err, fmt.Errorf("failed to get enclave %s", cfg.Kurtosis.EnclaveName))
+		}
+
+		for _, name := range args {
+			if err := restartService(ctx, enclaveCtx, name); err != nil {
+				return err
+			}
+			slog.With("service", name).Info("L1 service restarted")
+		}
+
+		return nil
+	},
+}
+
+// devContainers resolves service names to the containers of the dev L1 of the selected instance, so only
+// containers carrying its stack label are touched. A name may omit the instance prefix, e.g. localnet-l1-geth.
+func devContainers(ctx context.Context, client *docker.Client, names []string) ([]string, error) {
+	stackContainers, err := client.StackContainers(ctx, docker.L1Stack())
+	if err != nil {
+		return nil, err
+	}
+
+	containers := make([]string, 0, len(names))
+	for _, name := range names {
+		index := slices.IndexFunc(stackContainers, func(ctr docker.ContainerStatus) bool {
+			return ctr.Name == name || ctr.Name == configs.ScopedName(name)
+		})
+		if index < 0 {
+			return nil, fmt.Errorf("%s is not a service of the dev L1 stack %s", name, docker.L1Stack())
+		}
+		containers = append(containers, stackContainers[index].Name)
+	}
+	return containers, nil
+}
+
+func restartService(ctx context.Context, enclaveCtx *enclaves.EnclaveContext, name string) error {
+	params, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return err
+	}
+
+	result, err := enclaveCtx.RunStarlarkScriptBlocking(ctx, restartServiceScript,
+		config.NewRunStarlarkConfig(config.WithSerializedParams(string(params))))
+	if err != nil {
+		return errors.Join(err, fmt.Errorf("failed to restart service %s", name))
+	}
+
+	switch {
+	case result.InterpretationError != nil:
+		return fmt.Errorf("failed to restart service %s: %s", name, result.InterpretationError.String())
+	case len(result.ValidationErrors) > 0:
+		return fmt.Errorf("failed to restart service %s: %s", name, result.ValidationErrors[0].String())
+	case result.ExecutionError != nil:
+		return fmt.Errorf("failed to restart service %s: %s", name, result.ExecutionError.String())
+	}
+
+	return nil
+}
+
+func kurtosisStatus(ctx context.Context, enclaveName string) (Status, error) {
+	status := Status{Backend: configs.L1BackendKurtosis, Enclave: enclaveName, Services: []ServiceStatus{}}
+
+	kurtosisCtx, err := kurtosis_context.NewKurtosisContextFromLocalEngine()
+	if err != nil {
+		return Status{}, errors.Join(err, errors.New("failed to create kurtosis context"))
+	}
+
+	info, err := kurtosisCtx.GetEnclave(ctx, enclaveName)
+	if err != nil {
+		return Status{}, errors.Join(err, fmt.Errorf("failed to get enclave %s", enclaveName))
+	}
+
+	status.State = strings.ToLower(strings.TrimPrefix(info.GetContainersStatus().String(), "EnclaveContainersStatus_"))
+	if info.GetContainersStatus() != kurtosis_engine_rpc_api_bindings.EnclaveContainersStatus_EnclaveContainersStatus_RUNNING {
+		return status, nil
+	}
+
+	enclaveCtx, err := kurtosisCtx.GetEnclaveContext(ctx, enclaveName)
+	if err != nil {
+		return Status{}, errors.Join(err, fmt.Errorf("failed to get enclave context %s", enclaveName))
+	}
+
+	serviceNames, err := enclaveCtx.GetServices()
+	if err != nil {
+		return Status{}, errors.Join(err, errors.New("failed to list enclave services"))
+	}
+
+	for name := range serviceNames {
+		serviceCtx, err := enclaveCtx.GetServiceContext(string(name))
+		if err != nil {
+			return Status{}, errors.Join(err, fmt.Errorf("failed to get service context for %s", name))
+		}
+
+		service := ServiceStatus{Name: string(name), Ports: []PortStatus{}}
+		publicPorts := serviceCtx.GetPublicPorts()
+		for id, private := range serviceCtx.GetPrivatePorts() {
+			port := PortStatus{ID: id, PrivatePort: int(private.GetNumber())}
+			if public, ok := publicPorts[id]; ok {
+				port.PublicPort = int(public.GetNumber())
+				if scheme := public.GetMaybeApplicationProtocol(); scheme != "" {
+					port.URL = fmt.Sprintf("%s://%s:%d", scheme, cmp.Or(serviceCtx.GetMaybePublicIPAddress(), "127.0.0.1"), port.PublicPort)
+				}
+			}
+			service.Ports = append(service.Ports, port)
+		}
+
+		service.sortPorts()
+		status.Services = append(status.Services, service)
+	}
+
+	status.sortServices()
+	return status, nil
+}
+
+func devStatus(ctx context.Context) (Status, error) {
+	status := Status{Backend: configs.L1BackendDev, State: "missing", Services: []ServiceStatus{}}
+
+	// The host ports are those recorded by the last run, the defaults when there is none.
+	recorded, err := ports.Load(filepath.Join(configs.WorkDir(), ports.FileName))
+	if err != nil {
+		return Status{}, err
+	}
+	portStatus := func(id, scheme, container string, private int) PortStatus {
+		public := recorded.Get(ports.Name(container, private), private)
+		return PortStatus{ID: id, PrivatePort: private, PublicPort: public, URL: fmt.Sprintf("%s://127.0.0.1:%d", scheme, public)}
+	}
+	known := map[string][]PortStatus{
+		configs.ScopedName(devGethContainer): {
+			portStatus("rpc", "http", devGethContainer, devELRPCPort),
+			portStatus("ws", "ws", devGethContainer, devELWSPort),
+		},
+		configs.ScopedName(devBeaconContainer): {
+			portStatus("http", "http", devBeaconContainer, devBeaconPort),
+		},
+	}
+
+	err = withDockerClient(func(client *docker.Client) error {
+		containers, err := client.StackContainers(ctx, docker.L1Stack())
+		if err != nil {
+			return err
+		}
+
+		for _, ctr := range containers {
+			service := ServiceStatus{Name: ctr.Name, State: ctr.State, Ports: []PortStatus{}}
+			if statuses, ok := known[ctr.Name]; ok {
+				service.Ports = statuses
+			}
+			status.Services = append(status.Services, service)
+			if ctr.State == "running" {
+				status.State = "running"
+			} else if status.State == "missing" {
+				status.State = "stopped"
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Status{}, err
+	}
+
+	status.sortServices()
+	return status, nil
+}
+
+func (s *Status) sortServices() {
+	slices.SortFunc(s.Services, func(a, b ServiceStatus) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+}
+
+func (s *ServiceStatus) sortPorts() {
+	slices.SortFunc(s.Ports, func(a, b PortStatus) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+}
+
+// withDockerClient runs fn with a Docker client that is closed afterwards.
+func withDockerClient(fn func(client *docker.Client) error) error {
+	client, err := docker.New()
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer client.Close()
+
+	return fn(client)
+}
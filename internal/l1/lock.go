@@ -0,0 +1,64 @@
+package l1
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/l2/infra/git"
+	"github.com/compose-network/local-testnet/internal/lock"
+)
+
+// lockedPackage returns the locator of the configured Kurtosis package at the commit recorded in the lock file.
+func lockedPackage(name string) (string, error) {
+	path := configs.ScopedName(lock.FileName)
+	pins, found, err := lock.Load(path)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", fmt.Errorf("--locked requires %s. Run once without --locked to write it", path)
+	}
+	if pins.Kurtosis == nil || pins.Kurtosis.Name != name {
+		return "", fmt.Errorf("%s does not record the kurtosis package %s. Run without --locked to update it", path, name)
+	}
+
+	return pinnedLocator(*pins.Kurtosis), nil
+}
+
+// pinnedLocator returns the locator of a package at its resolved commit.
+func pinnedLocator(pinned lock.Package) string {
+	locator, _, _ := strings.Cut(pinned.Name, "@")
+	return locator + "@" + pinned.Commit
+}
+
+// resolvePackage resolves the commit of the Kurtosis package name, so the package runs at the commit the
+// lock file records. name is a package locator like github.com/ssvlabs/ssv-mini, optionally followed by
+// @<branch or tag>.
+func resolvePackage(ctx context.Context, name string) (lock.Package, error) {
+	locator, ref, _ := strings.Cut(name, "@")
+	parts := strings.Split(locator, "/")
+	if len(parts) < 3 {
+		return lock.Package{}, fmt.Errorf("package %s is not located in a git repository", name)
+	}
+
+	commit, err := git.NewCloner().RemoteCommit(ctx, "https://"+strings.Join(parts[:3], "/"), ref)
+	if err != nil {
+		return lock.Package{}, fmt.Errorf("failed to resolve kurtosis package %s: %w", name, err)
+	}
+
+	return lock.Package{Name: name, Commit: commit}, nil
+}
+
+// recordPackage records the package resolved by resolvePackage in the lock file.
+func recordPackage(pinned lock.Package) error {
+	path := configs.ScopedName(lock.FileName)
+	if err := lock.Record(path, lock.File{Kurtosis: &pinned}); err != nil {
+		return err
+	}
+	slog.With("package", pinned.Name, "commit", pinned.Commit, "path", path).Info("kurtosis package recorded in lock file")
+
+	return nil
+}
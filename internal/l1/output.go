@@ -0,0 +1,127 @@
+package l1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/logger"
+)
+
+type (
+	// Output describes the running L1 network in the form the L2 deployment consumes.
+	Output struct {
+		ChainID           int
+		ELRPCURL          string
+		CLBeaconURL       string
+		PrefundedAccounts []PrefundedAccount
+	}
+
+	PrefundedAccount struct {
+		Address    string `json:"address"`
+		PrivateKey string `json:"private_key"`
+	}
+
+	// serializedOutput mirrors the parts of the ethereum-package run output we rely on.
+	serializedOutput struct {
+		NetworkID         string             `json:"network_id"`
+		AllParticipants   []participant      `json:"all_participants"`
+		PreFundedAccounts []PrefundedAccount `json:"pre_funded_accounts"`
+	}
+
+	participant struct {
+		ELContext struct {
+			ServiceName string `json:"service_name"`
+			RPCHTTPURL  string `json:"rpc_http_url"`
+		} `json:"el_context"`
+		CLContext struct {
+			BeaconServiceName string `json:"beacon_service_name"`
+			BeaconHTTPURL     string `json:"beacon_http_url"`
+		} `json:"cl_context"`
+	}
+)
+
+// ApplyTo points the L2 configuration at this L1 network.
+// Wallet settings are only filled in when the config does not define them already.
+func (o Output) ApplyTo(cfg *configs.L2) {
+	cfg.L1ChainID = o.ChainID
+	cfg.L1ElURL = o.ELRPCURL
+	cfg.L1ClURL = o.CLBeaconURL
+
+	if cfg.Wallet.PrivateKey == "" && len(o.PrefundedAccounts) > 0 {
+		cfg.Wallet.PrivateKey = o.PrefundedAccounts[0].PrivateKey
+		cfg.Wallet.Address = o.PrefundedAccounts[0].Address
+	}
+}
+
+// parseSerializedOutput extracts the network description from the Kurtosis run output.
+// Wrapping packages (such as ssv-mini) may nest the ethereum-package output under their own keys,
+// so the first object holding "all_participants" is used.
+func parseSerializedOutput(raw string) (serializedOutput, error) {
+	if raw == "" {
+		return serializedOutput{}, errors.New("kurtosis package returned no output")
+	}
+
+	var doc any
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return serializedOutput{}, fmt.Errorf("failed to decode kurtosis output: %w", err)
+	}
+
+	node, ok := findObjectWithKey(doc, "all_participants")
+	if !ok {
+		return serializedOutput{}, errors.New("kurtosis output has no participants")
+	}
+
+	data, err := json.Marshal(node)
+	if err != nil {
+		return serializedOutput{}, fmt.Errorf("failed to re-encode kurtosis output: %w", err)
+	}
+
+	var out serializedOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return serializedOutput{}, fmt.Errorf("failed to decode network description: %w", err)
+	}
+
+	if len(out.AllParticipants) == 0 {
+		return serializedOutput{}, errors.New("kurtosis output has no participants")
+	}
+
+	return out, nil
+}
+
+func (s serializedOutput) chainID() (int, error) {
+	chainID, err := strconv.Atoi(s.NetworkID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid network_id '%s': %w", s.NetworkID, err)
+	}
+	return chainID, nil
+}
+
+func findObjectWithKey(node any, key string) (map[string]any, bool) {
+	switch v := node.(type) {
+	case map[string]any:
+		if _, ok := v[key]; ok {
+			return v, true
+		}
+		for _, child := range v {
+			if found, ok := findObjectWithKey(child, key); ok {
+				return found, true
+			}
+		}
+	case []any:
+		for _, child := range v {
+			if found, ok := findObjectWithKey(child, key); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// LogValue implements slog.LogValuer and hides the private key.
+func (a PrefundedAccount) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("address", a.Address), slog.String("private_key", logger.Redacted))
+}
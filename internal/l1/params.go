@@ -0,0 +1,169 @@
+package l1
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/compose-network/local-testnet/configs"
+	"gopkg.in/yaml.v3"
+
+	_ "embed"
+)
+
+//go:embed params.yaml
+var defaultParams []byte
+
+// buildParams merges the user overrides onto the embedded params.yaml and returns the serialized result.
+func buildParams(cfg configs.KurtosisConfig) (string, error) {
+	params := make(map[string]any)
+	if err := yaml.Unmarshal(defaultParams, &params); err != nil {
+		return "", fmt.Errorf("failed to decode embedded params.yaml: %w", err)
+	}
+
+	fileOverrides := make(map[string]any)
+	if cfg.ParamsFile != "" {
+		data, err := os.ReadFile(cfg.ParamsFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read params file %s: %w", cfg.ParamsFile, err)
+		}
+		if err := yaml.Unmarshal(data, &fileOverrides); err != nil {
+			return "", fmt.Errorf("failed to decode params file %s: %w", cfg.ParamsFile, err)
+		}
+	}
+
+	preregisteredPath := []string{"network", "network_params", "preregistered_validator_count"}
+	explicitPreregistered := hasKey(fileOverrides, preregisteredPath...) || hasKey(cfg.Params, preregisteredPath...)
+
+	mergeParams(params, fileOverrides)
+	mergeParams(params, cfg.Params)
+
+	if err := applyTypedOverrides(params, cfg, explicitPreregistered); err != nil {
+		return "", err
+	}
+
+	data, err := yaml.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode params: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// mergeParams deep-merges src into dst. Nested maps are merged key by key, any other value
+// (including lists such as network.participants) replaces the value in dst.
+func mergeParams(dst, src map[string]any) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeParams(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// applyTypedOverrides sets the dedicated config fields on every participant and node group.
+// When participant or validator counts change, preregistered_validator_count is recomputed, keeping
+// the extra validators reserved for SSV/Anchor nodes, unless the overrides set it explicitly.
+func applyTypedOverrides(params map[string]any, cfg configs.KurtosisConfig, explicitPreregistered bool) error {
+	network := childMap(params, "network")
+
+	participants, _ := network["participants"].([]any)
+	validatorsBefore := participantValidators(participants)
+
+	for i, p := range participants {
+		participant, ok := p.(map[string]any)
+		if !ok {
+			return fmt.Errorf("network.participants[%d] must be a map", i)
+		}
+		if cfg.ELImage != "" {
+			participant["el_image"] = cfg.ELImage
+		}
+		if cfg.CLImage != "" {
+			participant["cl_image"] = cfg.CLImage
+		}
+		if cfg.ParticipantCount > 0 {
+			participant["count"] = cfg.ParticipantCount
+		}
+		if cfg.ValidatorCount > 0 {
+			participant["validator_count"] = cfg.ValidatorCount
+		}
+	}
+
+	if (cfg.ParticipantCount > 0 || cfg.ValidatorCount > 0) && !explicitPreregistered {
+		networkParams := childMap(network, "network_params")
+		if current, ok := toInt(networkParams["preregistered_validator_count"]); ok {
+			networkParams["preregistered_validator_count"] = current - validatorsBefore + participantValidators(participants)
+		}
+	}
+
+	nodes := childMap(params, "nodes")
+	if cfg.SSVNodeCount > 0 {
+		childMap(nodes, "ssv")["count"] = cfg.SSVNodeCount
+	}
+	if cfg.AnchorNodeCount > 0 {
+		childMap(nodes, "anchor")["count"] = cfg.AnchorNodeCount
+	}
+
+	return nil
+}
+
+// participantValidators returns the total number of validators run by the EL/CL participants.
+func participantValidators(participants []any) int {
+	total := 0
+	for _, p := range participants {
+		participant, _ := p.(map[string]any)
+		count, ok := toInt(participant["count"])
+		if !ok {
+			count = 1
+		}
+		validators, _ := toInt(participant["validator_count"])
+		total += count * validators
+	}
+	return total
+}
+
+// childMap returns the nested map under key, creating it when missing.
+func childMap(parent map[string]any, key string) map[string]any {
+	child, ok := parent[key].(map[string]any)
+	if !ok {
+		child = make(map[string]any)
+		parent[key] = child
+	}
+	return child
+}
+
+// hasKey reports whether the nested key path exists, without modifying the map.
+func hasKey(m map[string]any, path ...string) bool {
+	for i, key := range path {
+		value, ok := m[key]
+		if !ok {
+			return false
+		}
+		if i == len(path)-1 {
+			return true
+		}
+		if m, ok = value.(map[string]any); !ok {
+			return false
+		}
+	}
+	return false
+}
+
+func toInt(value any) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case string:
+		var n int
+		if _, err := fmt.Sscanf(v, "%d", &n); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
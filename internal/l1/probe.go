@@ -0,0 +1,92 @@
+package l1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/kurtosis-tech/kurtosis/api/golang/engine/lib/kurtosis_context"
+)
+
+// Service name prefixes ethereum-package gives the first participant, e.g. el-1-geth-lighthouse.
+const (
+	firstELServicePrefix = "el-1-"
+	firstCLServicePrefix = "cl-1-"
+)
+
+// Probe reads the endpoints of an already running Kurtosis enclave, addressed through endpointHost.
+// Prefunded accounts are only reported by a package run, so the returned Output has none.
+func Probe(ctx context.Context, enclaveName, endpointHost string) (Output, error) {
+	kurtosisCtx, err := kurtosis_context.NewKurtosisContextFromLocalEngine()
+	if err != nil {
+		return Output{}, errors.Join(err, errors.New("failed to create kurtosis context"))
+	}
+
+	enclaveCtx, err := kurtosisCtx.GetEnclaveContext(ctx, enclaveName)
+	if err != nil {
+		return Output{}, errors.Join(err, fmt.Errorf("failed to get enclave context %s", enclaveName))
+	}
+
+	services, err := enclaveCtx.GetServices()
+	if err != nil {
+		return Output{}, errors.Join(err, errors.New("failed to list enclave services"))
+	}
+
+	var names []string
+	for name := range services {
+		names = append(names, string(name))
+	}
+	slices.Sort(names)
+
+	findService := func(prefix string) (string, error) {
+		for _, name := range names {
+			if strings.HasPrefix(name, prefix) {
+				return name, nil
+			}
+		}
+		return "", fmt.Errorf("enclave %s has no service starting with %s", enclaveName, prefix)
+	}
+
+	elService, err := findService(firstELServicePrefix)
+	if err != nil {
+		return Output{}, err
+	}
+	clService, err := findService(firstCLServicePrefix)
+	if err != nil {
+		return Output{}, err
+	}
+
+	elURL, err := publicURL(enclaveCtx, elService, elRPCPortID, endpointHost)
+	if err != nil {
+		return Output{}, err
+	}
+	clURL, err := publicURL(enclaveCtx, clService, clHTTPPortID, endpointHost)
+	if err != nil {
+		return Output{}, err
+	}
+
+	// The endpoint host is meant for containers, this process queries the chain ID through its own view of the host.
+	localELURL, err := publicURL(enclaveCtx, elService, elRPCPortID, localHost())
+	if err != nil {
+		return Output{}, err
+	}
+	client, err := ethclient.DialContext(ctx, localELURL)
+	if err != nil {
+		return Output{}, errors.Join(err, fmt.Errorf("failed to connect to %s", localELURL))
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return Output{}, errors.Join(err, errors.New("failed to query L1 chain ID"))
+	}
+
+	return Output{
+		ChainID:     int(chainID.Int64()),
+		ELRPCURL:    elURL,
+		CLBeaconURL: clURL,
+	}, nil
+}
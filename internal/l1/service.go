@@ -6,44 +6,109 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/lock"
+	"github.com/compose-network/local-testnet/internal/ports"
+	"github.com/kurtosis-tech/kurtosis/api/golang/core/lib/enclaves"
 	config "github.com/kurtosis-tech/kurtosis/api/golang/core/lib/starlark_run_config"
 	"github.com/kurtosis-tech/kurtosis/api/golang/engine/lib/kurtosis_context"
-
-	_ "embed"
 )
 
-//go:embed params.yaml
-var params []byte
-
 const (
-	enclaveName         = "localnet"
-	kurtosisPackageName = "github.com/ssvlabs/ssv-mini"
+	DefaultEnclaveName = "localnet"
+	DefaultPackageName = "github.com/ssvlabs/ssv-mini"
+
+	// Port IDs used by ethereum-package for the EL JSON-RPC and CL beacon API.
+	elRPCPortID  = "rpc"
+	clHTTPPortID = "http"
+
+	// DefaultEndpointHost is how L2 containers reach ports published by the enclave on the host.
+	DefaultEndpointHost = "host.docker.internal"
 )
 
-func start(ctx context.Context) error {
+// StartOptions controls how the L1 network is exposed to its consumers.
+type StartOptions struct {
+	// EndpointHost is the host name put into the returned URLs.
+	EndpointHost string
+	// Fund lists addresses topped up by backends that do not prefund them in genesis (dev).
+	Fund []string
+	// Locked runs the Kurtosis package at the commit recorded in the lock file instead of recording it.
+	Locked bool
+	// PortMode selects how the host ports of the dev backend are assigned.
+	PortMode ports.Mode
+}
+
+// Start launches the L1 network with the configured backend and returns its endpoints.
+func Start(ctx context.Context, cfg configs.L1, opts StartOptions) (Output, error) {
+	if err := cfg.Validate(); err != nil {
+		return Output{}, err
+	}
+
+	switch cfg.BackendOrDefault() {
+	case configs.L1BackendDev:
+		return startDev(ctx, cfg, opts)
+	default:
+		return startKurtosis(ctx, cfg.Kurtosis, opts.EndpointHost, opts.Locked)
+	}
+}
+
+// startKurtosis launches the L1 enclave. Endpoints use the host-published ports of the first
+// participant, addressed through endpointHost, so they are reachable outside the enclave.
+// With locked the package runs at the commit recorded in the lock file, otherwise it runs at the commit its
+// ref resolves to, which is then recorded.
+func startKurtosis(ctx context.Context, cfg configs.KurtosisConfig, endpointHost string, locked bool) (Output, error) {
+	params, err := buildParams(cfg)
+	if err != nil {
+		return Output{}, err
+	}
+
+	var (
+		packageName string
+		resolved    lock.Package
+	)
+	if locked {
+		if packageName, err = lockedPackage(cfg.PackageName); err != nil {
+			return Output{}, err
+		}
+	} else {
+		if resolved, err = resolvePackage(ctx, cfg.PackageName); err != nil {
+			return Output{}, err
+		}
+		packageName = pinnedLocator(resolved)
+	}
+
+	slog.Debug("kurtosis package params", slog.String("params", params))
+
 	kurtosisCtx, err := kurtosis_context.NewKurtosisContextFromLocalEngine()
 	if err != nil {
-		return errors.Join(err, errors.New("failed to create kurtosis context"))
+		return Output{}, errors.Join(err, errors.New("failed to create kurtosis context"))
 	}
 
-	enclaveCtx, err := kurtosisCtx.CreateEnclave(ctx, enclaveName)
+	enclaveCtx, err := kurtosisCtx.CreateEnclave(ctx, cfg.EnclaveName)
 	if err != nil {
-		return errors.Join(err, errors.New("failed to create enclave"))
+		return Output{}, errors.Join(err, errors.New("failed to create enclave"))
 	}
 
 	outputCh, cancel, err := enclaveCtx.RunStarlarkRemotePackage(
 		ctx,
-		kurtosisPackageName,
-		config.NewRunStarlarkConfig(config.WithSerializedParams(string(params))))
+		packageName,
+		config.NewRunStarlarkConfig(config.WithSerializedParams(params)))
 	if err != nil {
-		return errors.Join(err, errors.New("failed to run starlark package"))
+		return Output{}, errors.Join(err, errors.New("failed to run starlark package"))
 	}
 	defer cancel()
 
 	var jsonResponse string
 
 	for output := range outputCh {
-		slog.Debug(output.String())
+		// The run finished event carries the serialized output with the private keys of the
+		// pre-funded accounts, so it is not logged verbatim.
+		ev := output.GetRunFinishedEvent()
+		if ev != nil {
+			slog.With("component", "kurtosis").Debug("run finished", slog.Bool("successful", ev.GetIsRunSuccessful()))
+		} else {
+			slog.With("component", "kurtosis").Debug(output.String())
+		}
 
 		info := output.GetInfo()
 		if info != nil {
@@ -69,18 +134,76 @@ func start(ctx context.Context) error {
 		kurtosisErr := output.GetError()
 		if kurtosisErr != nil {
 			slog.Error(kurtosisErr.String())
-			return fmt.Errorf("kurtosis package returned error: %s", kurtosisErr.String())
+			return Output{}, fmt.Errorf("kurtosis package returned error: %s", kurtosisErr.String())
 		}
 
-		ev := output.GetRunFinishedEvent()
 		if ev != nil && ev.SerializedOutput != nil {
 			jsonResponse = *ev.SerializedOutput
 		}
 	}
 
-	slog.Info("Kurtosis package launched",
-		slog.String("package", kurtosisPackageName),
-		slog.String("response", jsonResponse))
+	slog.Info("Kurtosis package launched", slog.String("package", packageName))
+
+	serialized, err := parseSerializedOutput(jsonResponse)
+	if err != nil {
+		return Output{}, errors.Join(err, errors.New("failed to parse kurtosis package output"))
+	}
+
+	chainID, err := serialized.chainID()
+	if err != nil {
+		return Output{}, err
+	}
+
+	node := serialized.AllParticipants[0]
+	elURL, err := publicURL(enclaveCtx, node.ELContext.ServiceName, elRPCPortID, endpointHost)
+	if err != nil {
+		slog.With("err", err.Error()).Warn("falling back to enclave-internal EL URL")
+		elURL = node.ELContext.RPCHTTPURL
+	}
+
+	clURL, err := publicURL(enclaveCtx, node.CLContext.BeaconServiceName, clHTTPPortID, endpointHost)
+	if err != nil {
+		slog.With("err", err.Error()).Warn("falling back to enclave-internal CL URL")
+		clURL = node.CLContext.BeaconHTTPURL
+	}
+
+	if !locked {
+		if err := recordPackage(resolved); err != nil {
+			slog.With("err", err.Error()).Warn("failed to record kurtosis package in lock file")
+		}
+	}
+
+	output := Output{
+		ChainID:           chainID,
+		ELRPCURL:          elURL,
+		CLBeaconURL:       clURL,
+		PrefundedAccounts: serialized.PreFundedAccounts,
+	}
+
+	slog.Info("L1 network is ready",
+		slog.Int("chain_id", output.ChainID),
+		slog.String("el_url", output.ELRPCURL),
+		slog.String("cl_url", output.CLBeaconURL),
+		slog.Int("prefunded_accounts", len(output.PrefundedAccounts)))
+
+	return output, nil
+}
+
+// publicURL resolves the host-published port of an enclave service.
+func publicURL(enclaveCtx *enclaves.EnclaveContext, serviceName, portID, host string) (string, error) {
+	if serviceName == "" {
+		return "", errors.New("service name is missing in kurtosis output")
+	}
+
+	serviceCtx, err := enclaveCtx.GetServiceContext(serviceName)
+	if err != nil {
+		return "", errors.Join(err, fmt.Errorf("failed to get service context for %s", serviceName))
+	}
+
+	port, ok := serviceCtx.GetPublicPorts()[portID]
+	if !ok {
+		return "", fmt.Errorf("service %s has no public port '%s'", serviceName, portID)
+	}
 
-	return nil
+	return fmt.Sprintf("http://%s:%d", host, port.GetNumber()), nil
 }
@@ -1,32 +1,92 @@
 package blockscout
 
 import (
+	"bytes"
 	"embed"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/docker"
 )
 
-//go:embed docker-compose.blockscout.yml
+//go:embed docker-compose.blockscout.yml.tmpl
 var embeddedComposeFS embed.FS
 
 const composeFileName = "docker-compose.blockscout.yml"
 
-func ensureComposeFile(localnetDir string) (string, error) {
+type (
+	composeChain struct {
+		Suffix         string
+		EnvSuffix      string
+		DisplayName    string
+		DBContainer    string
+		RedisContainer string
+		ProxyContainer string
+	}
+
+	composeTemplateData struct {
+		Project string
+		Stack   string
+		Network string
+		Images  images
+		Chains  []composeChain
+	}
+)
+
+// ensureComposeFile renders the blockscout compose file for the given rollups into localnetDir.
+func ensureComposeFile(localnetDir string, rollupConfigs []RollupConfig, images images) (string, error) {
 	composePath := filepath.Join(localnetDir, composeFileName)
 
-	content, err := embeddedComposeFS.ReadFile(composeFileName)
+	tmplContent, err := embeddedComposeFS.ReadFile(composeFileName + ".tmpl")
+	if err != nil {
+		return "", fmt.Errorf("failed to read embedded %s template: %w", composeFileName, err)
+	}
+
+	tmpl, err := template.New(composeFileName).Parse(string(tmplContent))
 	if err != nil {
-		return "", fmt.Errorf("failed to read embedded %s: %w", composeFileName, err)
+		return "", fmt.Errorf("failed to parse %s template: %w", composeFileName, err)
+	}
+
+	data := composeTemplateData{
+		Project: docker.ComposeProject(),
+		Stack:   docker.L2Stack(),
+		Network: docker.L2Network(),
+		Images:  images,
+		Chains:  make([]composeChain, 0, len(rollupConfigs)),
+	}
+	for _, config := range rollupConfigs {
+		suffix := config.Name.Suffix()
+		data.Chains = append(data.Chains, composeChain{
+			Suffix:         suffix,
+			EnvSuffix:      config.Name.EnvSuffix(),
+			DisplayName:    "Rollup " + strings.ToUpper(suffix),
+			DBContainer:    containerName("blockscout", suffix, "db"),
+			RedisContainer: containerName("blockscout", suffix, "redis"),
+			ProxyContainer: containerName("blockscout", suffix, "proxy"),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute %s template: %w", composeFileName, err)
 	}
 
 	if err := os.MkdirAll(filepath.Dir(composePath), 0755); err != nil {
 		return "", fmt.Errorf("failed to create %s directory: %w", localnetDir, err)
 	}
 
-	if err := os.WriteFile(composePath, content, 0644); err != nil {
+	if err := os.WriteFile(composePath, buf.Bytes(), 0644); err != nil {
 		return "", fmt.Errorf("failed to write %s: %w", composeFileName, err)
 	}
 
 	return composePath, nil
 }
+
+// containerName joins the name parts and scopes the result to the selected instance.
+func containerName(parts ...string) string {
+	return configs.ScopedName(strings.Join(parts, "-"))
+}
@@ -7,8 +7,6 @@ import (
 	"os"
 	"path/filepath"
 	"text/template"
-
-	"github.com/compose-network/local-testnet/configs"
 )
 
 //go:embed nginx.conf.tmpl
@@ -35,15 +33,7 @@ func generateNginxConfigs(networksDir string, rollupConfigs []RollupConfig) erro
 	}
 
 	for _, config := range rollupConfigs {
-		var suffix string
-		switch config.Name {
-		case configs.L2ChainNameRollupA:
-			suffix = "a"
-		case configs.L2ChainNameRollupB:
-			suffix = "b"
-		default:
-			return fmt.Errorf("unknown rollup name: %s", config.Name)
-		}
+		suffix := config.Name.Suffix()
 
 		rollupDir := filepath.Join(networksDir, string(config.Name))
 		if err := os.MkdirAll(rollupDir, 0755); err != nil {
@@ -51,8 +41,8 @@ func generateNginxConfigs(networksDir string, rollupConfigs []RollupConfig) erro
 		}
 
 		data := nginxTemplateData{
-			BackendService:  fmt.Sprintf("%s-%s", backendServiceName, suffix),
-			FrontendService: fmt.Sprintf("%s-%s", frontendServiceName, suffix),
+			BackendService:  containerName(backendServiceName, suffix),
+			FrontendService: containerName(frontendServiceName, suffix),
 			BackendPort:     backendPort,
 			FrontendPort:    frontendPort,
 		}
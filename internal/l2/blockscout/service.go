@@ -9,7 +9,9 @@ import (
 
 	"github.com/compose-network/local-testnet/configs"
 	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
+	"github.com/compose-network/local-testnet/internal/lock"
 	"github.com/compose-network/local-testnet/internal/logger"
+	"github.com/compose-network/local-testnet/internal/ports"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -17,11 +19,17 @@ const (
 	blockscoutVersion         = "9.0.2"
 	blockscoutFrontendVersion = "v2.3.5"
 	nginxVersion              = "1.29.3-alpine"
+	postgresVersion           = "17"
+	redisVersion              = "7-alpine"
 
 	backendServiceName  = "blockscout"
 	frontendServiceName = "blockscout-frontend"
 	backendPort         = 4000
 	frontendPort        = 3000
+
+	// proxyPort is the container port of the per-chain nginx proxy published on the host, by default
+	// in the chain's host port block (19000, 29000, ...).
+	proxyPort = 80
 )
 
 type (
@@ -31,19 +39,50 @@ type (
 		ELHostName            string
 		RPCPort               int
 		WSPort                int
+		PublicPort            int
 		SystemConfigProxyAddr common.Address
 	}
+	// images are the images the Blockscout services run.
+	images struct {
+		Postgres string
+		Redis    string
+		Backend  string
+		Frontend string
+		Nginx    string
+	}
+
 	Service struct {
 		localnetDir string
 		networksDir string
+		images      images
 		logger      *slog.Logger
 	}
 )
 
-func New(localnetDir, networksDir string) *Service {
+// PublicPort returns the host port of a chain's explorer from the resolved port map, or its default.
+func PublicPort(cfg configs.L2, name configs.L2ChainName) int {
+	return cfg.HostPorts.Get(publicPortName(name), cfg.HostPortBase(name)+configs.BlockscoutPortOffset)
+}
+
+// HostPorts lists the host ports published by Blockscout, with their defaults.
+func HostPorts(cfg configs.L2) []ports.Port {
+	hostPorts := make([]ports.Port, 0, len(cfg.ChainConfigs))
+	for _, name := range cfg.ChainNames() {
+		hostPorts = append(hostPorts, ports.Port{Name: publicPortName(name), Default: cfg.HostPortBase(name) + configs.BlockscoutPortOffset})
+	}
+	return hostPorts
+}
+
+func publicPortName(name configs.L2ChainName) string {
+	return ports.Name(fmt.Sprintf("blockscout-%s-proxy", name.Suffix()), proxyPort)
+}
+
+// New creates the Blockscout service. Images pinned by pins are run by digest.
+func New(localnetDir, networksDir string, pins lock.File) *Service {
 	return &Service{
 		localnetDir: localnetDir,
 		networksDir: networksDir,
+		images:      newImages(pins),
 		logger:      logger.Named("blockscout"),
 	}
 }
@@ -51,21 +90,17 @@ func New(localnetDir, networksDir string) *Service {
 func (s *Service) Run(ctx context.Context, rollupConfigs []RollupConfig, l1RPCURL, l1BeaconURL string) error {
 	s.logger.Info("starting Blockscout service")
 
-	if len(rollupConfigs) != 2 {
-		return fmt.Errorf("expected exactly 2 chain configs, got %d", len(rollupConfigs))
+	if len(rollupConfigs) == 0 {
+		return fmt.Errorf("expected at least one chain config")
 	}
 
-	if err := generateNginxConfigs(s.networksDir, rollupConfigs); err != nil {
-		return fmt.Errorf("failed to generate nginx configs: %w", err)
-	}
-
-	composePath, err := ensureComposeFile(s.localnetDir)
+	composePath, err := s.Render(rollupConfigs)
 	if err != nil {
-		return fmt.Errorf("failed to prepare blockscout compose file: %w", err)
+		return err
 	}
 
 	envVars := s.buildAllEnvVars(rollupConfigs, l1RPCURL, l1BeaconURL)
-	s.logger.With("env", envVars).Info("environment variables built. Starting services")
+	s.logger.With("env", logger.Env(envVars)).Info("environment variables built. Starting services")
 
 	if err := docker.ComposeUp(ctx, composePath, envVars); err != nil {
 		return fmt.Errorf("failed to start blockscout services: %w", err)
@@ -76,35 +111,53 @@ func (s *Service) Run(ctx context.Context, rollupConfigs []RollupConfig, l1RPCUR
 	return nil
 }
 
+// Render writes the nginx configs and the compose file for the given rollups and returns the compose file path.
+func (s *Service) Render(rollupConfigs []RollupConfig) (string, error) {
+	if err := generateNginxConfigs(s.networksDir, rollupConfigs); err != nil {
+		return "", fmt.Errorf("failed to generate nginx configs: %w", err)
+	}
+
+	composePath, err := ensureComposeFile(s.localnetDir, rollupConfigs, s.images)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare blockscout compose file: %w", err)
+	}
+
+	return composePath, nil
+}
+
+// Images lists the images the Blockscout services run, by digest where pins pins them.
+func Images(pins lock.File) []string {
+	images := newImages(pins)
+	return []string{images.Postgres, images.Redis, images.Backend, images.Frontend, images.Nginx}
+}
+
+func newImages(pins lock.File) images {
+	return images{
+		Postgres: pins.Image("postgres:" + postgresVersion),
+		Redis:    pins.Image("redis:" + redisVersion),
+		Backend:  pins.Image("ghcr.io/blockscout/blockscout-optimism:" + blockscoutVersion),
+		Frontend: pins.Image("ghcr.io/blockscout/frontend:" + blockscoutFrontendVersion),
+		Nginx:    pins.Image("nginx:" + nginxVersion),
+	}
+}
+
 func (s *Service) buildAllEnvVars(chainConfigs []RollupConfig, l1RPCURL, l1BeaconURL string) map[string]string {
 	envVars := make(map[string]string)
 
-	envVars["BLOCKSCOUT_VERSION"] = blockscoutVersion
-	envVars["BLOCKSCOUT_FRONTEND_VERSION"] = blockscoutFrontendVersion
-	envVars["NGINX_VERSION"] = nginxVersion
 	envVars["BLOCKSCOUT_BACKEND_PORT"] = fmt.Sprintf("%d", backendPort)
 	envVars["BLOCKSCOUT_FRONTEND_PORT"] = fmt.Sprintf("%d", frontendPort)
-	envVars["BLOCKSCOUT_A_PUBLIC_PORT"] = "19000"
-	envVars["BLOCKSCOUT_B_PUBLIC_PORT"] = "29000"
-
-	envVars["BLOCKSCOUT_A_BACKEND_CONTAINER"] = fmt.Sprintf("%s-a", backendServiceName)
-	envVars["BLOCKSCOUT_B_BACKEND_CONTAINER"] = fmt.Sprintf("%s-b", backendServiceName)
-	envVars["BLOCKSCOUT_A_FRONTEND_CONTAINER"] = fmt.Sprintf("%s-a", frontendServiceName)
-	envVars["BLOCKSCOUT_B_FRONTEND_CONTAINER"] = fmt.Sprintf("%s-b", frontendServiceName)
-
-	rollupANginxConf := filepath.Join(s.networksDir, "rollup-a", "blockscout-nginx.conf")
-	rollupBNginxConf := filepath.Join(s.networksDir, "rollup-b", "blockscout-nginx.conf")
-	envVars["ROLLUP_A_NGINX_CONF"] = rollupANginxConf
-	envVars["ROLLUP_B_NGINX_CONF"] = rollupBNginxConf
-
 	envVars["INDEXER_OPTIMISM_L1_RPC"] = l1RPCURL
 	envVars["INDEXER_BEACON_RPC_URL"] = l1BeaconURL
 
-	prefixes := []string{"ROLLUP_A_", "ROLLUP_B_"}
+	for _, config := range chainConfigs {
+		envSuffix := config.Name.EnvSuffix()
+		envVars[fmt.Sprintf("BLOCKSCOUT_%s_PUBLIC_PORT", envSuffix)] = fmt.Sprintf("%d", config.PublicPort)
+		envVars[fmt.Sprintf("BLOCKSCOUT_%s_BACKEND_CONTAINER", envSuffix)] = containerName(backendServiceName, config.Name.Suffix())
+		envVars[fmt.Sprintf("BLOCKSCOUT_%s_FRONTEND_CONTAINER", envSuffix)] = containerName(frontendServiceName, config.Name.Suffix())
+		envVars[fmt.Sprintf("ROLLUP_%s_NGINX_CONF", envSuffix)] = filepath.Join(s.networksDir, string(config.Name), "blockscout-nginx.conf")
 
-	for i, config := range chainConfigs {
 		rollupVars := s.buildRollupEnvVars(config)
-		mergeWithPrefix(envVars, rollupVars, prefixes[i])
+		mergeWithPrefix(envVars, rollupVars, fmt.Sprintf("ROLLUP_%s_", envSuffix))
 	}
 
 	return envVars
@@ -0,0 +1,317 @@
+package l2
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/compose-network/local-testnet/configs"
+	dockerclient "github.com/compose-network/local-testnet/internal/docker"
+	"github.com/compose-network/local-testnet/internal/l2/infra/archive"
+	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
+	"github.com/compose-network/local-testnet/internal/l2/infra/git"
+	"gopkg.in/yaml.v3"
+)
+
+// Entries of a bundle archive. Repositories, the op-deployer cache and solc keep their directory layout
+// below their entry.
+const (
+	bundleManifestName     = "manifest.yaml"
+	bundleImagesName       = "images.tar"
+	bundleRepositoriesName = "repositories"
+	bundleCacheName        = "op-deployer-cache"
+	bundleSolcName         = "solc"
+)
+
+// bundleManifest describes the content of a bundle, it is informational only.
+type bundleManifest struct {
+	CreatedAt    time.Time         `yaml:"created-at"`
+	Images       []string          `yaml:"images"`
+	Repositories map[string]string `yaml:"repositories"`
+}
+
+// ExportBundle writes everything an L2 deployment downloads to the archive at archivePath: the images,
+// the cloned repositories including the forge libraries of compose-contracts, the op-deployer artifact
+// cache and the solc compilers of forge. Pulled images missing locally are pulled first, built images
+// and clones must exist, so a deployment has to run before the export.
+func ExportBundle(ctx context.Context, cfg configs.L2, archivePath string) error {
+	rootDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	localnetDir := filepath.Join(rootDir, configs.WorkDir())
+	servicesDir := filepath.Join(localnetDir, servicesDirName)
+
+	client, err := dockerclient.New()
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer client.Close()
+
+	images, err := prepareBundleImages(ctx, client, cfg)
+	if err != nil {
+		return err
+	}
+
+	manifest := bundleManifest{CreatedAt: time.Now().UTC(), Images: images, Repositories: make(map[string]string)}
+	cloner := git.NewCloner()
+	var errs []error
+	sourceRepositories := cfg.SourceRepositories()
+	for _, name := range slices.Sorted(maps.Keys(sourceRepositories)) {
+		if sourceRepositories[name].URL == "" {
+			slog.With("name", name).Info("repository uses a local path, not bundled")
+			continue
+		}
+		commit, err := cloner.Commit(ctx, filepath.Join(servicesDir, string(name)))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("repository %s is not cloned: %w", name, err))
+			continue
+		}
+		manifest.Repositories[string(name)] = commit
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("run a deployment first so the repositories are cloned: %w", errors.Join(errs...))
+	}
+
+	contractsLib := filepath.Join(servicesDir, string(configs.RepositoryNameComposeContracts), "L1-settlement", "lib")
+	if _, ok := manifest.Repositories[string(configs.RepositoryNameComposeContracts)]; ok && isEmptyDir(contractsLib) {
+		slog.With("path", contractsLib).Warn("forge libraries of compose-contracts are missing. Run a deployment first to bundle them")
+	}
+
+	if err := writeBundle(ctx, client, archivePath, manifest, localnetDir, servicesDir); err != nil {
+		if removeErr := os.Remove(archivePath); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+			slog.With("err", removeErr.Error()).Warn("failed to remove incomplete bundle")
+		}
+		return err
+	}
+
+	slog.With("path", archivePath, "images", len(images), "repositories", len(manifest.Repositories)).Info("bundle exported")
+
+	return nil
+}
+
+// prepareBundleImages returns the images to bundle, pulling the registry images missing locally.
+func prepareBundleImages(ctx context.Context, client *dockerclient.Client, cfg configs.L2) ([]string, error) {
+	pulled := pulledImages(cfg)
+
+	var missing []string
+	for _, image := range pulled {
+		exists, err := client.ImageExists(ctx, image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check image '%s': %w", image, err)
+		}
+		if !exists {
+			if err := client.PullImage(ctx, image); err != nil {
+				return nil, fmt.Errorf("failed to pull image '%s': %w", image, err)
+			}
+		}
+	}
+	built := builtImages(cfg)
+	for _, image := range built {
+		exists, err := client.ImageExists(ctx, image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check image '%s': %w", image, err)
+		}
+		if !exists {
+			missing = append(missing, image)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("images %s are not built. Run a deployment first", strings.Join(missing, ", "))
+	}
+
+	images := append(pulled, built...)
+	slices.Sort(images)
+	return slices.Compact(images), nil
+}
+
+// writeBundle writes the manifest, the images and the directories to the archive.
+func writeBundle(ctx context.Context, client *dockerclient.Client, archivePath string, manifest bundleManifest, localnetDir, servicesDir string) error {
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle manifest: %w", err)
+	}
+
+	// A tar entry needs its size up front, so the images are saved to a temporary file first.
+	imagesFile, err := os.CreateTemp("", "localnet-images-*.tar")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(imagesFile.Name())
+	if err := client.SaveImages(ctx, manifest.Images, imagesFile); err != nil {
+		imagesFile.Close()
+		return err
+	}
+	if err := imagesFile.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", imagesFile.Name(), err)
+	}
+
+	writer, err := archive.Create(archivePath)
+	if err != nil {
+		return err
+	}
+
+	err = func() error {
+		if err := writer.AddBytes(bundleManifestName, data); err != nil {
+			return err
+		}
+		if err := writer.AddFile(bundleImagesName, imagesFile.Name()); err != nil {
+			return err
+		}
+		for _, name := range slices.Sorted(maps.Keys(manifest.Repositories)) {
+			slog.With("name", name).Info("adding repository to bundle")
+			if err := writer.AddDir(bundleRepositoriesName+"/"+name, filepath.Join(servicesDir, name)); err != nil {
+				return err
+			}
+		}
+
+		cacheDir := filepath.Join(localnetDir, stateDirName, ".cache")
+		if isEmptyDir(cacheDir) {
+			slog.With("path", cacheDir).Warn("op-deployer artifact cache is missing. An offline deployment cannot download the contract artifacts")
+		} else if err := writer.AddDir(bundleCacheName, cacheDir); err != nil {
+			return err
+		}
+
+		if solcDir, err := solcDir(); err == nil && !isEmptyDir(solcDir) {
+			if err := writer.AddDir(bundleSolcName, solcDir); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+
+	return errors.Join(err, writer.Close())
+}
+
+// ImportBundle loads a bundle written by ExportBundle: the images into Docker, the repositories into the
+// work directory of the selected instance, replacing existing clones, and the caches to where the
+// deployment and forge look for them.
+func ImportBundle(ctx context.Context, archivePath string) error {
+	rootDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	localnetDir := filepath.Join(rootDir, configs.WorkDir())
+	servicesDir := filepath.Join(localnetDir, servicesDirName)
+	cacheDir := filepath.Join(localnetDir, stateDirName, ".cache")
+
+	client, err := dockerclient.New()
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer client.Close()
+
+	replaced := make(map[string]bool)
+	err = archive.Walk(archivePath, func(header *tar.Header, r io.Reader) error {
+		first, rest := archive.Split(header.Name)
+		switch first {
+		case bundleManifestName:
+			var manifest bundleManifest
+			if err := yaml.NewDecoder(r).Decode(&manifest); err != nil {
+				return fmt.Errorf("failed to parse bundle manifest: %w", err)
+			}
+			slog.With("created_at", manifest.CreatedAt, "images", manifest.Images, "repositories", manifest.Repositories).
+				Info("importing bundle")
+			return nil
+		case bundleImagesName:
+			slog.Info("loading docker images")
+			return client.LoadImages(ctx, r)
+		case bundleRepositoriesName:
+			// Each repository is extracted below its own clone directory, so its symlinks cannot reach another one.
+			name, path := archive.Split(rest)
+			if name == "" {
+				return nil
+			}
+			if name == "." || name == ".." {
+				return fmt.Errorf("unexpected entry %s in bundle", header.Name)
+			}
+			if !replaced[name] {
+				slog.With("name", name).Info("importing repository")
+				if err := os.RemoveAll(filepath.Join(servicesDir, name)); err != nil {
+					return fmt.Errorf("failed to remove existing clone of %s: %w", name, err)
+				}
+				replaced[name] = true
+			}
+			return archive.Extract(header, r, filepath.Join(servicesDir, name), path)
+		case bundleCacheName:
+			return archive.Extract(header, r, cacheDir, rest)
+		case bundleSolcName:
+			dir, err := solcDir()
+			if err != nil {
+				return err
+			}
+			return archive.Extract(header, r, dir, rest)
+		default:
+			return fmt.Errorf("unexpected entry %s in bundle", header.Name)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import bundle %s: %w", archivePath, err)
+	}
+
+	slog.With("path", archivePath, "repositories", len(replaced)).Info("bundle imported. Deploy with --offline to use it")
+
+	return nil
+}
+
+// checkOfflineImages verifies that every image of the deployment exists locally, so an offline deployment
+// fails up front instead of when Docker tries to pull or build one.
+func checkOfflineImages(ctx context.Context, cfg configs.L2) error {
+	client, err := dockerclient.New()
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer client.Close()
+
+	var missing []string
+	for _, image := range append(pulledImages(cfg), builtImages(cfg)...) {
+		exists, err := client.ImageExists(ctx, image)
+		if err != nil {
+			return fmt.Errorf("failed to check image '%s': %w", image, err)
+		}
+		if !exists {
+			missing = append(missing, image)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("images %s are not available offline. Import them with localnet bundle import", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// builtImages lists the images the deployment builds from a repository.
+func builtImages(cfg configs.L2) []string {
+	var images []string
+	for _, service := range docker.Services(cfg) {
+		if service.BuildFrom != "" {
+			images = append(images, service.Image)
+		}
+	}
+
+	slices.Sort(images)
+	return slices.Compact(images)
+}
+
+// solcDir returns the directory forge installs solc compilers to.
+func solcDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".svm"), nil
+}
+
+func isEmptyDir(path string) bool {
+	entries, err := os.ReadDir(path)
+	return err != nil || len(entries) == 0
+}
@@ -0,0 +1,172 @@
+package checkpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/compose-network/local-testnet/configs"
+	fsjson "github.com/compose-network/local-testnet/internal/l2/infra/filesystem/json"
+	"github.com/compose-network/local-testnet/internal/l2/l1deployment"
+	"github.com/compose-network/local-testnet/internal/l2/l2runtime/contracts"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const fileName = "checkpoint.json"
+
+// Phase identifies a single step of the L2 deployment.
+type Phase string
+
+const (
+	PhaseClone      Phase = "clone"
+	PhaseL1         Phase = "l1"
+	PhaseL2Config   Phase = "l2-config"
+	PhaseL2Runtime  Phase = "l2-runtime"
+	PhaseBlockscout Phase = "blockscout"
+	PhaseOutput     Phase = "output"
+)
+
+// Phases lists all deployment phases in execution order.
+var Phases = []Phase{PhaseClone, PhaseL1, PhaseL2Config, PhaseL2Runtime, PhaseBlockscout, PhaseOutput}
+
+type (
+	// Checkpoint is the persisted progress of an L2 deployment.
+	Checkpoint struct {
+		ConfigFingerprint string                                                            `json:"configFingerprint"`
+		Completed         map[Phase]time.Time                                               `json:"completed"`
+		DeploymentState   *l1deployment.DeploymentState                                     `json:"deploymentState,omitempty"`
+		DeployedContracts map[configs.L2ChainName]map[contracts.ContractName]common.Address `json:"deployedContracts,omitempty"`
+	}
+
+	// Store reads and writes the checkpoint file under the state directory.
+	Store struct {
+		path   string
+		reader *fsjson.Reader
+		writer *fsjson.Writer
+	}
+)
+
+// ParsePhase converts a user supplied phase name into a Phase.
+func ParsePhase(name string) (Phase, error) {
+	phase := Phase(strings.ToLower(strings.TrimSpace(name)))
+	if !slices.Contains(Phases, phase) {
+		names := make([]string, 0, len(Phases))
+		for _, p := range Phases {
+			names = append(names, string(p))
+		}
+		return "", fmt.Errorf("unknown phase '%s' (expected one of: %s)", name, strings.Join(names, ", "))
+	}
+	return phase, nil
+}
+
+// New creates an empty checkpoint bound to the given configuration.
+func New(cfg configs.L2) Checkpoint {
+	return Checkpoint{
+		ConfigFingerprint: Fingerprint(cfg),
+		Completed:         make(map[Phase]time.Time),
+	}
+}
+
+// Fingerprint hashes the parts of the configuration that the persisted phase outputs depend on.
+// Resuming with a different L1 or chain setup would mix artifacts of two deployments. Host ports are
+// left out: the auto port mode may reassign them on every run.
+func Fingerprint(cfg configs.L2) string {
+	chainIDs := make(map[configs.L2ChainName]int, len(cfg.ChainConfigs))
+	for name, chain := range cfg.ChainConfigs {
+		chainIDs[name] = chain.ID
+	}
+
+	data, _ := json.Marshal(struct {
+		L1ChainID          int
+		L1ElURL            string
+		ComposeNetworkName string
+		WalletAddress      string
+		ChainIDs           map[configs.L2ChainName]int
+		Dispute            configs.DisputeConfig
+	}{
+		L1ChainID:          cfg.L1ChainID,
+		L1ElURL:            cfg.L1ElURL,
+		ComposeNetworkName: cfg.ComposeNetworkName,
+		WalletAddress:      cfg.Wallet.Address,
+		ChainIDs:           chainIDs,
+		Dispute:            cfg.Dispute,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// IsCompleted reports whether the phase finished successfully.
+func (c *Checkpoint) IsCompleted(phase Phase) bool {
+	_, ok := c.Completed[phase]
+	return ok
+}
+
+// MarkCompleted records the phase as finished.
+func (c *Checkpoint) MarkCompleted(phase Phase) {
+	c.Completed[phase] = time.Now().UTC()
+}
+
+// InvalidateFrom forgets the given phase and every phase after it, so they run again.
+// Later phases consume the outputs of earlier ones, so they cannot be kept on their own.
+func (c *Checkpoint) InvalidateFrom(phase Phase) {
+	idx := slices.Index(Phases, phase)
+	if idx < 0 {
+		return
+	}
+	for _, p := range Phases[idx:] {
+		delete(c.Completed, p)
+	}
+	if idx <= slices.Index(Phases, PhaseL1) {
+		c.DeploymentState = nil
+	}
+	if idx <= slices.Index(Phases, PhaseL2Runtime) {
+		c.DeployedContracts = nil
+	}
+}
+
+// NewStore creates a checkpoint store in the given state directory.
+func NewStore(stateDir string) *Store {
+	return &Store{
+		path:   filepath.Join(stateDir, fileName),
+		reader: fsjson.NewReader(),
+		writer: fsjson.NewWriter(),
+	}
+}
+
+// Path returns the location of the checkpoint file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load reads the checkpoint file. The boolean result is false when no checkpoint exists yet.
+func (s *Store) Load() (Checkpoint, bool, error) {
+	if _, err := os.Stat(s.path); errors.Is(err, fs.ErrNotExist) {
+		return Checkpoint{}, false, nil
+	}
+
+	var cp Checkpoint
+	if err := s.reader.ReadJSON(s.path, &cp); err != nil {
+		return Checkpoint{}, false, fmt.Errorf("failed to read checkpoint %s: %w", s.path, err)
+	}
+	if cp.Completed == nil {
+		cp.Completed = make(map[Phase]time.Time)
+	}
+
+	return cp, true, nil
+}
+
+// Save persists the checkpoint file.
+func (s *Store) Save(cp Checkpoint) error {
+	if err := s.writer.WriteJSON(s.path, cp); err != nil {
+		return fmt.Errorf("failed to write checkpoint %s: %w", s.path, err)
+	}
+	return nil
+}
@@ -28,22 +28,33 @@ var deployCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to get working directory: %w", err)
 		}
-		localnetDir := filepath.Join(rootDir, localnetDirName)
+		localnetDir := filepath.Join(rootDir, configs.WorkDir())
 		networksDir := filepath.Join(localnetDir, networksDirName)
 		servicesDir := filepath.Join(localnetDir, servicesDirName)
 
-		composePath, err := docker.EnsureComposeFile(localnetDir)
+		cfg := configs.Values.L2
+		for _, name := range []configs.RepositoryName{configs.RepositoryNameOpGeth, configs.RepositoryNamePublisher} {
+			if image := cfg.PrebuiltImage(name); image != "" && (target == string(name) || target == "all") {
+				return fmt.Errorf("%s runs the prebuilt image %s. Configure l2.repositories.%s instead to build it from source", name, image, name)
+			}
+		}
+		if err := applyRecordedHostPorts(&cfg, rootDir); err != nil {
+			return err
+		}
+
+		composePath, err := docker.EnsureComposeFile(localnetDir, cfg)
 		if err != nil {
 			return fmt.Errorf("failed to prepare docker-compose file: %w", err)
 		}
 
 		envBuilder := docker.NewEnvBuilder(rootDir, networksDir, servicesDir)
-		envVars, err := envBuilder.BuildComposeEnv(configs.Values.L2, common.Address{})
+		envVars, err := envBuilder.BuildComposeEnv(cfg, common.Address{})
 		if err != nil {
 			return err
 		}
+		envBuilder.AddSourceRevisions(cmd.Context(), cfg, envVars)
 
-		services := mapServices(target)
+		services := mapServices(target, cfg.ChainNames())
 		ctx := cmd.Context()
 		slog.With("services", services).Info("building services from local sources")
 		if err := docker.ComposeBuild(ctx, composePath, envVars, services...); err != nil {
@@ -60,13 +71,13 @@ var deployCmd = &cobra.Command{
 	},
 }
 
-func mapServices(target string) []string {
+func mapServices(target string, chains []configs.L2ChainName) []string {
 	switch target {
 	case "op-geth":
-		return []string{"op-geth-a", "op-geth-b"}
+		return docker.OpGethServices(chains)
 	case "publisher":
-		return []string{"publisher"}
+		return []string{docker.PublisherService}
 	default:
-		return []string{"publisher", "op-geth-a", "op-geth-b"}
+		return append([]string{docker.PublisherService}, docker.OpGethServices(chains)...)
 	}
 }
@@ -1,132 +1,98 @@
 package l2
 
 import (
-	"github.com/spf13/viper"
+	"github.com/compose-network/local-testnet/internal/flags"
 )
 
-// flagDef defines a command-line flag with its configuration.
-type (
-	flagType interface {
-		string | int | bool
-	}
-
-	flagDef[T flagType] struct {
-		name         string
-		viperKey     string
-		defaultValue T
-		description  string
-	}
+// Deployment control flags. They only affect a single invocation, so they are not bound to viper.
+const (
+	resumeFlag    = "resume"
+	fromPhaseFlag = "from-phase"
+	dryRunFlag    = "dry-run"
+	planDirFlag   = "plan-dir"
+	yesFlag       = "yes"
+	rebuildFlag   = "rebuild"
 )
 
 var (
-	stringFlags = []flagDef[string]{
+	stringFlags = []flags.Def[string]{
 		// L1 connection
-		{"l1-el-url", "l2.l1-el-url", "", "L1 execution layer RPC URL"},
-		{"l1-cl-url", "l2.l1-cl-url", "", "L1 consensus layer RPC URL"},
-		{"compose-network-name", "l2.compose-network-name", "", "Compose network name for publisher registry"},
+		{Name: "l1-el-url", ViperKey: "l2.l1-el-url", Description: "L1 execution layer RPC URL"},
+		{Name: "l1-cl-url", ViperKey: "l2.l1-cl-url", Description: "L1 consensus layer RPC URL"},
+		{Name: "compose-network-name", ViperKey: "l2.compose-network-name", Description: "Compose network name for publisher registry"},
 
 		// Wallet
-		{"wallet-private-key", "l2.wallet.private-key", "", "Deployer wallet private key"},
-		{"wallet-address", "l2.wallet.address", "", "Deployer wallet address"},
-		{"coordinator-private-key", "l2.coordinator-private-key", "", "Coordinator private key"},
+		{Name: "wallet-private-key", ViperKey: "l2.wallet.private-key", Description: "Deployer wallet private key"},
+		{Name: "wallet-address", ViperKey: "l2.wallet.address", Description: "Deployer wallet address"},
+		{Name: "coordinator-private-key", ViperKey: "l2.coordinator-private-key", Description: "Coordinator private key"},
 
 		// Deployment
-		{"deployment-target", "l2.deployment-target", "live", "Deployment target (live or calldata)"},
-		{"genesis-balance-wei", "l2.genesis-balance-wei", "100000000000000000000000", "Genesis balance in wei for funded accounts (default: 100_000 ETH)"},
+		{Name: "deployment-target", ViperKey: "l2.deployment-target", Default: "live", Description: "Deployment target (live or calldata)"},
+		{Name: "genesis-balance-wei", ViperKey: "l2.genesis-balance-wei", Default: "100000000000000000000000", Description: "Genesis balance in wei for funded accounts (default: 100_000 ETH)"},
 
 		// Repositories (no defaults - must be explicitly set in config or via CLI)
-		{"op-geth-url", "l2.repositories.op-geth.url", "", "op-geth repository URL"},
-		{"op-geth-branch", "l2.repositories.op-geth.branch", "", "op-geth repository branch"},
-		{"publisher-url", "l2.repositories.publisher.url", "", "publisher repository URL"},
-		{"publisher-branch", "l2.repositories.publisher.branch", "", "publisher repository branch"},
-		{"compose-contracts-url", "l2.repositories.compose-contracts.url", "", "compose-contracts repository URL"},
-		{"compose-contracts-branch", "l2.repositories.compose-contracts.branch", "", "compose-contracts repository branch"},
-		{"sidecar-url", "l2.repositories.sidecar.url", "", "sidecar repository URL"},
-		{"sidecar-branch", "l2.repositories.sidecar.branch", "", "sidecar repository branch"},
+		{Name: "op-geth-url", ViperKey: "l2.repositories.op-geth.url", Description: "op-geth repository URL"},
+		{Name: "op-geth-branch", ViperKey: "l2.repositories.op-geth.branch", Description: "op-geth repository branch"},
+		{Name: "publisher-url", ViperKey: "l2.repositories.publisher.url", Description: "publisher repository URL"},
+		{Name: "publisher-branch", ViperKey: "l2.repositories.publisher.branch", Description: "publisher repository branch"},
+		{Name: "compose-contracts-url", ViperKey: "l2.repositories.compose-contracts.url", Description: "compose-contracts repository URL"},
+		{Name: "compose-contracts-branch", ViperKey: "l2.repositories.compose-contracts.branch", Description: "compose-contracts repository branch"},
+		{Name: "sidecar-url", ViperKey: "l2.repositories.sidecar.url", Description: "sidecar repository URL"},
+		{Name: "sidecar-branch", ViperKey: "l2.repositories.sidecar.branch", Description: "sidecar repository branch"},
 
 		// Images
-		{"op-deployer-tag", "l2.images.op-deployer.tag", "v0.4.5", "op-deployer image tag"},
-		{"op-node-tag", "l2.images.op-node.tag", "v1.16.2", "op-node image tag"},
-		{"op-proposer-tag", "l2.images.op-proposer.tag", "v1.10.0", "op-proposer image tag"},
-		{"op-batcher-tag", "l2.images.op-batcher.tag", "v1.16.2", "op-batcher image tag"},
+		{Name: "op-deployer-tag", ViperKey: "l2.images.op-deployer.tag", Default: "v0.4.5", Description: "op-deployer image tag"},
+		{Name: "op-node-tag", ViperKey: "l2.images.op-node.tag", Default: "v1.16.2", Description: "op-node image tag"},
+		{Name: "op-proposer-tag", ViperKey: "l2.images.op-proposer.tag", Default: "v1.10.0", Description: "op-proposer image tag"},
+		{Name: "op-batcher-tag", ViperKey: "l2.images.op-batcher.tag", Default: "v1.16.2", Description: "op-batcher image tag"},
 
 		// Dispute config
-		{"dispute-network-name", "l2.dispute.network-name", "", "Dispute network name"},
-		{"dispute-explorer-url", "l2.dispute.explorer-url", "", "Dispute explorer URL"},
-		{"dispute-explorer-api-url", "l2.dispute.explorer-api-url", "", "Dispute explorer API URL"},
-		{"dispute-verifier-address", "l2.dispute.verifier-address", "", "Verifier contract address"},
-		{"dispute-owner-address", "l2.dispute.owner-address", "", "Owner address"},
-		{"dispute-proposer-address", "l2.dispute.proposer-address", "", "Proposer address"},
-		{"dispute-aggregation-vkey", "l2.dispute.aggregation-vkey", "", "Aggregation verification key"},
-		{"dispute-guardian-address", "l2.dispute.guardian-address", "", "Guardian address"},
-		{"dispute-game-init-bond", "l2.dispute.dispute-game-init-bond", "80000000000000000", "Initial bond for dispute games in wei"},
+		{Name: "dispute-network-name", ViperKey: "l2.dispute.network-name", Description: "Dispute network name"},
+		{Name: "dispute-explorer-url", ViperKey: "l2.dispute.explorer-url", Description: "Dispute explorer URL"},
+		{Name: "dispute-explorer-api-url", ViperKey: "l2.dispute.explorer-api-url", Description: "Dispute explorer API URL"},
+		{Name: "dispute-verifier-address", ViperKey: "l2.dispute.verifier-address", Description: "Verifier contract address"},
+		{Name: "dispute-owner-address", ViperKey: "l2.dispute.owner-address", Description: "Owner address"},
+		{Name: "dispute-proposer-address", ViperKey: "l2.dispute.proposer-address", Description: "Proposer address"},
+		{Name: "dispute-aggregation-vkey", ViperKey: "l2.dispute.aggregation-vkey", Description: "Aggregation verification key"},
+		{Name: "dispute-guardian-address", ViperKey: "l2.dispute.guardian-address", Description: "Guardian address"},
+		{Name: "dispute-game-init-bond", ViperKey: "l2.dispute.dispute-game-init-bond", Default: "80000000000000000", Description: "Initial bond for dispute games in wei"},
 	}
 
-	intFlags = []flagDef[int]{
+	intFlags = []flags.Def[int]{
 		// L1 connection
-		{"l1-chain-id", "l2.l1-chain-id", 0, "L1 chain ID"},
-
-		// Chain configs
-		{"rollup-a-id", "l2.chain-configs.rollup-a.id", 77777, "Rollup A chain ID"},
-		{"rollup-a-rpc-port", "l2.chain-configs.rollup-a.rpc-port", 18545, "Rollup A RPC port"},
-		{"rollup-b-id", "l2.chain-configs.rollup-b.id", 88888, "Rollup B chain ID"},
-		{"rollup-b-rpc-port", "l2.chain-configs.rollup-b.rpc-port", 28545, "Rollup B RPC port"},
-
-		// Flashblocks
-		{"flashblocks-rollup-a-rpc-port", "l2.flashblocks.rollup-a-rpc-port", 17545, "Rollup A op-rbuilder RPC port"},
-		{"flashblocks-rollup-b-rpc-port", "l2.flashblocks.rollup-b-rpc-port", 27545, "Rollup B op-rbuilder RPC port"},
-
-		// Sidecar
-		{"sidecar-rollup-a-api-port", "l2.sidecar.rollup-a-api-port", 17090, "Rollup A sidecar API port"},
-		{"sidecar-rollup-b-api-port", "l2.sidecar.rollup-b-api-port", 27090, "Rollup B sidecar API port"},
+		{Name: "l1-chain-id", ViperKey: "l2.l1-chain-id", Description: "L1 chain ID"},
 
 		// Dispute config
-		{"dispute-proof-maturity-delay-seconds", "l2.dispute.proof-maturity-delay-seconds", 604800, "Proof maturity delay in seconds (default: 7 days)"},
-		{"dispute-game-finality-delay-seconds", "l2.dispute.dispute-game-finality-delay-seconds", 302400, "Dispute game finality delay in seconds (default: 3.5 days)"},
+		{Name: "dispute-proof-maturity-delay-seconds", ViperKey: "l2.dispute.proof-maturity-delay-seconds", Default: 604800, Description: "Proof maturity delay in seconds (default: 7 days)"},
+		{Name: "dispute-game-finality-delay-seconds", ViperKey: "l2.dispute.dispute-game-finality-delay-seconds", Default: 302400, Description: "Dispute game finality delay in seconds (default: 3.5 days)"},
 	}
 
-	boolFlags = []flagDef[bool]{
-		{"blockscout-enabled", "l2.blockscout.enabled", false, "Enable Blockscout block explorer"},
-		{"flashblocks-enabled", "l2.flashblocks.enabled", false, "Enable flashblocks support (op-rbuilder and rollup-boost)"},
-		{"sidecar-enabled", "l2.sidecar.enabled", false, "Enable sidecar for cross-chain coordination (requires flashblocks)"},
+	boolFlags = []flags.Def[bool]{
+		{Name: "blockscout-enabled", ViperKey: "l2.blockscout.enabled", Description: "Enable Blockscout block explorer"},
+		{Name: "flashblocks-enabled", ViperKey: "l2.flashblocks.enabled", Description: "Enable flashblocks support (op-rbuilder and rollup-boost)"},
+		{Name: "sidecar-enabled", ViperKey: "l2.sidecar.enabled", Description: "Enable sidecar for cross-chain coordination (requires flashblocks)"},
 	}
 )
 
 func init() {
-	if err := declareFlags(stringFlags); err != nil {
+	if err := flags.Declare(CMD.Flags(), stringFlags); err != nil {
 		panic(err)
 	}
-	if err := declareFlags(intFlags); err != nil {
+	if err := flags.Declare(CMD.Flags(), intFlags); err != nil {
 		panic(err)
 	}
-	if err := declareFlags(boolFlags); err != nil {
+	if err := flags.Declare(CMD.Flags(), boolFlags); err != nil {
 		panic(err)
 	}
+	CMD.Flags().Bool(resumeFlag, false, "Resume a previous deployment, skipping phases recorded in the checkpoint file")
+	CMD.Flags().String(fromPhaseFlag, "", "Force a phase and all following phases to run again (clone, l1, l2-config, l2-runtime, blockscout, output). Implies --resume")
+	CMD.Flags().Bool(dryRunFlag, false, "Write the generated artifacts to the plan directory and print what the deployment would do, without touching Docker or the L1")
+	CMD.Flags().String(planDirFlag, "", "Plan directory for --dry-run (default <work dir>/plan)")
+	CMD.Flags().Bool(yesFlag, false, "Deploy to the L1 even if the preflight finds an unknown chain or insufficient funds")
+	CMD.Flags().Bool(rebuildFlag, false, "Build the local images even if they were built from the checked out sources")
 	CMD.AddCommand(compileCmd)
 	CMD.AddCommand(deployCmd)
-}
-
-// declareFlags declares multiple flags and binds them to viper configuration keys.
-func declareFlags[T flagType](flags []flagDef[T]) error {
-	for _, flag := range flags {
-		if err := declareFlag(flag.name, flag.viperKey, flag.defaultValue, flag.description); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-// declareFlag declares a single flag and binds it to a viper configuration key.
-// The type parameter T determines the flag type (string, int, or bool).
-func declareFlag[T flagType](flagName, viperKey string, defaultValue T, description string) error {
-	var zero T
-	switch any(zero).(type) {
-	case string:
-		CMD.Flags().String(flagName, any(defaultValue).(string), description)
-	case int:
-		CMD.Flags().Int(flagName, any(defaultValue).(int), description)
-	case bool:
-		CMD.Flags().Bool(flagName, any(defaultValue).(bool), description)
-	}
-	return viper.BindPFlag(viperKey, CMD.Flags().Lookup(flagName))
+	CMD.AddCommand(downCmd)
+	CMD.AddCommand(cleanCmd)
+	CMD.AddCommand(statusCmd)
 }
@@ -0,0 +1,227 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Writer writes a gzip compressed tar archive.
+type Writer struct {
+	file *os.File
+	gz   *gzip.Writer
+	tw   *tar.Writer
+}
+
+// Create creates the archive file at path, replacing an existing one.
+func Create(path string) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	gz := gzip.NewWriter(file)
+	return &Writer{file: file, gz: gz, tw: tar.NewWriter(gz)}, nil
+}
+
+// AddBytes adds a regular file with the given content.
+func (w *Writer) AddBytes(name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}
+	if err := w.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := w.tw.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	return nil
+}
+
+// AddFile adds the file at src as name.
+func (w *Writer) AddFile(name, src string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	return w.addEntry(name, src, info)
+}
+
+// AddDir adds the tree at src under name, keeping modes and symlinks.
+func (w *Writer) AddDir(name, src string) error {
+	return filepath.WalkDir(src, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, filePath)
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return w.addEntry(path.Join(name, filepath.ToSlash(rel)), filePath, info)
+	})
+}
+
+func (w *Writer) addEntry(name, src string, info fs.FileInfo) error {
+	var link string
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		link = target
+	}
+
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", src, err)
+	}
+	header.Name = name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	// Owners of the exporting host mean nothing on the importing one.
+	header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+
+	if err := w.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s: %w", src, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := io.Copy(w.tw, file); err != nil {
+		return fmt.Errorf("failed to add %s: %w", src, err)
+	}
+	return nil
+}
+
+// Close finishes the archive.
+func (w *Writer) Close() error {
+	return errors.Join(w.tw.Close(), w.gz.Close(), w.file.Close())
+}
+
+// Walk calls fn for every entry of the archive at path, in order. fn may read the entry's content from r.
+func Walk(path string, fn func(header *tar.Header, r io.Reader) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if !filepath.IsLocal(header.Name) {
+			return fmt.Errorf("archive %s contains the unsafe path %s", path, header.Name)
+		}
+		if err := fn(header, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// Extract writes the entry to name below root, creating parent directories as needed. Nothing is written
+// through a symlink, so a symlink extracted earlier cannot redirect later entries out of root, and
+// symlinks may only point below root.
+func Extract(header *tar.Header, r io.Reader, root, name string) error {
+	if name != "" && !filepath.IsLocal(name) {
+		return fmt.Errorf("entry %s has the unsafe path %s", header.Name, name)
+	}
+	dest := filepath.Join(root, name)
+
+	if err := checkNoSymlink(root, filepath.Dir(name)); err != nil {
+		return fmt.Errorf("failed to extract %s: %w", header.Name, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	// An existing symlink at dest is replaced instead of followed.
+	if info, err := os.Lstat(dest); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+		if err := os.Remove(dest); err != nil {
+			return err
+		}
+	}
+
+	mode := header.FileInfo().Mode()
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(dest, mode.Perm()|0700)
+	case tar.TypeSymlink:
+		target := filepath.Join(filepath.Dir(dest), header.Linkname)
+		if rel, err := filepath.Rel(root, target); filepath.IsAbs(header.Linkname) || err != nil || !filepath.IsLocal(rel) {
+			return fmt.Errorf("symlink %s points outside of %s", header.Name, root)
+		}
+		if err := os.RemoveAll(dest); err != nil {
+			return err
+		}
+		return os.Symlink(header.Linkname, dest)
+	case tar.TypeReg:
+		file, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(file, r); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to extract %s: %w", header.Name, err)
+		}
+		return file.Close()
+	default:
+		return fmt.Errorf("unsupported entry %s of type %c", header.Name, header.Typeflag)
+	}
+}
+
+// checkNoSymlink fails when an existing element of the directory dir below root is a symlink.
+func checkNoSymlink(root, dir string) error {
+	current := root
+	for _, elem := range strings.Split(dir, string(filepath.Separator)) {
+		if elem == "." || elem == "" {
+			continue
+		}
+		current = filepath.Join(current, elem)
+		info, err := os.Lstat(current)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("refusing to write through the symlink %s", current)
+		}
+	}
+	return nil
+}
+
+// Split returns the first element of an entry name and the rest, e.g. "repositories" and "op-geth/go.mod".
+func Split(name string) (first, rest string) {
+	first, rest, _ = strings.Cut(strings.TrimSuffix(name, "/"), "/")
+	return first, rest
+}
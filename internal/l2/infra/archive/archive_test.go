@@ -0,0 +1,187 @@
+package archive
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testEntry struct {
+	name     string
+	typeflag byte
+	linkname string
+	content  string
+}
+
+func (e testEntry) header() *tar.Header {
+	mode := int64(0644)
+	if e.typeflag == tar.TypeDir {
+		mode = 0755
+	}
+	return &tar.Header{
+		Name:     e.name,
+		Typeflag: e.typeflag,
+		Linkname: e.linkname,
+		Mode:     mode,
+		Size:     int64(len(e.content)),
+	}
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []testEntry
+		wantErr string
+		// files maps paths below root to their expected content after extraction.
+		files map[string]string
+	}{
+		{
+			name: "regular file in a new directory",
+			entries: []testEntry{
+				{name: "dir/file", typeflag: tar.TypeReg, content: "data"},
+			},
+			files: map[string]string{"dir/file": "data"},
+		},
+		{
+			name: "parent directory name",
+			entries: []testEntry{
+				{name: "../escaped", typeflag: tar.TypeReg, content: "data"},
+			},
+			wantErr: "unsafe path",
+		},
+		{
+			name: "nested parent directory name",
+			entries: []testEntry{
+				{name: "dir/../../escaped", typeflag: tar.TypeReg, content: "data"},
+			},
+			wantErr: "unsafe path",
+		},
+		{
+			name: "absolute name",
+			entries: []testEntry{
+				{name: "/tmp/escaped", typeflag: tar.TypeReg, content: "data"},
+			},
+			wantErr: "unsafe path",
+		},
+		{
+			name: "symlink below root",
+			entries: []testEntry{
+				{name: "dir/file", typeflag: tar.TypeReg, content: "data"},
+				{name: "link", typeflag: tar.TypeSymlink, linkname: "dir/file"},
+			},
+			files: map[string]string{"link": "data"},
+		},
+		{
+			name: "absolute symlink target",
+			entries: []testEntry{
+				{name: "link", typeflag: tar.TypeSymlink, linkname: "/etc"},
+			},
+			wantErr: "points outside",
+		},
+		{
+			name: "symlink target escaping root",
+			entries: []testEntry{
+				{name: "link", typeflag: tar.TypeSymlink, linkname: "../outside"},
+			},
+			wantErr: "points outside",
+		},
+		{
+			name: "nested symlink target escaping root",
+			entries: []testEntry{
+				{name: "dir/link", typeflag: tar.TypeSymlink, linkname: "../../outside"},
+			},
+			wantErr: "points outside",
+		},
+		{
+			name: "entry written through an extracted symlink",
+			entries: []testEntry{
+				{name: "dir/", typeflag: tar.TypeDir},
+				{name: "link", typeflag: tar.TypeSymlink, linkname: "dir"},
+				{name: "link/file", typeflag: tar.TypeReg, content: "data"},
+			},
+			wantErr: "refusing to write through the symlink",
+		},
+		{
+			name: "file replacing an extracted symlink",
+			entries: []testEntry{
+				{name: "target", typeflag: tar.TypeReg, content: "original"},
+				{name: "link", typeflag: tar.TypeSymlink, linkname: "target"},
+				{name: "link", typeflag: tar.TypeReg, content: "replaced"},
+			},
+			files: map[string]string{"target": "original", "link": "replaced"},
+		},
+		{
+			name: "unsupported entry type",
+			entries: []testEntry{
+				{name: "fifo", typeflag: tar.TypeFifo},
+			},
+			wantErr: "unsupported entry",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+
+			var err error
+			for _, entry := range tt.entries {
+				name := filepath.FromSlash(strings.TrimSuffix(entry.name, "/"))
+				if err = Extract(entry.header(), strings.NewReader(entry.content), root, name); err != nil {
+					break
+				}
+			}
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Extract() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract() failed: %v", err)
+			}
+			for file, want := range tt.files {
+				got, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(file)))
+				if err != nil {
+					t.Fatalf("failed to read %s: %v", file, err)
+				}
+				if string(got) != want {
+					t.Errorf("%s = %q, want %q", file, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckNoSymlink(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "dir", "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("dir", filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("nested", filepath.Join(root, "dir", "nested-link")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dir     string
+		wantErr bool
+	}{
+		{dir: ".", wantErr: false},
+		{dir: "dir", wantErr: false},
+		{dir: "dir/nested", wantErr: false},
+		{dir: "missing/child", wantErr: false},
+		{dir: "link", wantErr: true},
+		{dir: "link/nested", wantErr: true},
+		{dir: "dir/nested-link", wantErr: true},
+	}
+	for _, tt := range tests {
+		err := checkNoSymlink(root, filepath.FromSlash(tt.dir))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkNoSymlink(%q) error = %v, wantErr %v", tt.dir, err, tt.wantErr)
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/compose-network/local-testnet/internal/logger"
 )
 
 // ComposeBuild builds docker compose services.
@@ -74,8 +76,11 @@ func composeRunMultiFile(ctx context.Context, composeFilePaths []string, env map
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
 	}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	stdout, stderr := logger.CommandOutput("docker-compose")
+	defer stdout.Close()
+	defer stderr.Close()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("docker compose %s failed: %w", strings.Join(args, " "), err)
@@ -95,8 +100,11 @@ func composeRun(ctx context.Context, composeFilePath string, env map[string]stri
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
 	}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	stdout, stderr := logger.CommandOutput("docker-compose")
+	defer stdout.Close()
+	defer stderr.Close()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("docker compose %s failed: %w", strings.Join(args, " "), err)
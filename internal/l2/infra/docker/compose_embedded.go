@@ -1,109 +1,106 @@
 package docker
 
 import (
+	"bytes"
 	"embed"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/compose-network/local-testnet/configs"
 )
 
-//go:embed docker-compose.yml docker-compose.flashblocks.yml docker-compose.sidecar.yml
+//go:embed compose-partials.tmpl docker-compose.yml.tmpl docker-compose.flashblocks.yml.tmpl docker-compose.sidecar.yml.tmpl
 var embeddedComposeFS embed.FS
 
 const (
 	composeFileName            = "docker-compose.yml"
 	composeFlashblocksFileName = "docker-compose.flashblocks.yml"
 	composeSidecarFileName     = "docker-compose.sidecar.yml"
+	composePartialsFileName    = "compose-partials.tmpl"
+
+	// envFileName is picked up automatically by docker compose from the project directory,
+	// which lets the rendered files be used by hand (e.g. docker compose -f .localnet/docker-compose.yml ps).
+	envFileName = ".env"
 )
 
-// EnsureComposeFile ensures the docker-compose.yml file exists in the specified directory
-// and returns its path. It always writes the embedded content to ensure the file is up-to-date.
+// EnsureComposeFile renders docker-compose.yml for the configured chains into the specified directory
+// and returns its path. It always re-renders the file to ensure it is up-to-date.
 // This allows the compose file to be used from anywhere (including when running
 // the binary from a different directory).
-func EnsureComposeFile(localnetDir string) (string, error) {
-	composePath := filepath.Join(localnetDir, composeFileName)
-
-	content, err := getDockerComposeContent()
-	if err != nil {
-		return "", err
-	}
-
-	if err := os.MkdirAll(filepath.Dir(composePath), 0755); err != nil {
-		return "", fmt.Errorf("failed to create %s directory: %w", localnetDir, err)
-	}
-
-	if err := os.WriteFile(composePath, []byte(content), 0644); err != nil {
-		return "", fmt.Errorf("failed to write %s: %w", composeFileName, err)
-	}
+func EnsureComposeFile(localnetDir string, cfg configs.L2) (string, error) {
+	return renderComposeFile(localnetDir, composeFileName, NewComposeSpec(cfg))
+}
 
-	return composePath, nil
+// EnsureFlashblocksComposeFile renders docker-compose.flashblocks.yml for the configured chains
+// into the specified directory and returns its path.
+func EnsureFlashblocksComposeFile(localnetDir string, cfg configs.L2) (string, error) {
+	return renderComposeFile(localnetDir, composeFlashblocksFileName, NewComposeSpec(cfg))
 }
 
-// getDockerComposeContent returns the embedded docker-compose.yml content.
-func getDockerComposeContent() (string, error) {
-	content, err := embeddedComposeFS.ReadFile(composeFileName)
-	if err != nil {
-		return "", fmt.Errorf("failed to read embedded %s: %w", composeFileName, err)
-	}
-	return string(content), nil
+// EnsureSidecarComposeFile renders docker-compose.sidecar.yml for the configured chains
+// into the specified directory and returns its path.
+func EnsureSidecarComposeFile(localnetDir string, cfg configs.L2) (string, error) {
+	return renderComposeFile(localnetDir, composeSidecarFileName, NewComposeSpec(cfg))
 }
 
-// EnsureFlashblocksComposeFile ensures the docker-compose.flashblocks.yml file exists
-// in the specified directory and returns its path.
-func EnsureFlashblocksComposeFile(localnetDir string) (string, error) {
-	composePath := filepath.Join(localnetDir, composeFlashblocksFileName)
+// WriteEnvFile writes the compose environment to the .env file next to the rendered compose files.
+// The file contains secrets, so it is only readable by the current user.
+func WriteEnvFile(localnetDir string, env map[string]string) (string, error) {
+	envPath := filepath.Join(localnetDir, envFileName)
 
-	content, err := getFlashblocksComposeContent()
-	if err != nil {
-		return "", err
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by localnet. Do not commit: contains private keys.\n")
+	for _, key := range slices.Sorted(maps.Keys(env)) {
+		fmt.Fprintf(&buf, "%s=%s\n", key, quoteEnvValue(env[key]))
 	}
 
-	if err := os.MkdirAll(filepath.Dir(composePath), 0755); err != nil {
+	if err := os.MkdirAll(localnetDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create %s directory: %w", localnetDir, err)
 	}
 
-	if err := os.WriteFile(composePath, []byte(content), 0644); err != nil {
-		return "", fmt.Errorf("failed to write %s: %w", composeFlashblocksFileName, err)
+	if err := os.WriteFile(envPath, buf.Bytes(), 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", envFileName, err)
 	}
 
-	return composePath, nil
+	return envPath, nil
 }
 
-// getFlashblocksComposeContent returns the embedded docker-compose.flashblocks.yml content.
-func getFlashblocksComposeContent() (string, error) {
-	content, err := embeddedComposeFS.ReadFile(composeFlashblocksFileName)
+// renderComposeFile executes the embedded <fileName>.tmpl template and writes the result to localnetDir.
+func renderComposeFile(localnetDir, fileName string, spec ComposeSpec) (string, error) {
+	composePath := filepath.Join(localnetDir, fileName)
+
+	tmpl, err := template.New(fileName).
+		Funcs(template.FuncMap{"volumes": Volumes, "sourceLabel": sourceLabel}).
+		ParseFS(embeddedComposeFS, composePartialsFileName, fileName+".tmpl")
 	if err != nil {
-		return "", fmt.Errorf("failed to read embedded %s: %w", composeFlashblocksFileName, err)
+		return "", fmt.Errorf("failed to parse %s template: %w", fileName, err)
 	}
-	return string(content), nil
-}
-
-// EnsureSidecarComposeFile ensures the docker-compose.sidecar.yml file exists
-// in the specified directory and returns its path.
-func EnsureSidecarComposeFile(localnetDir string) (string, error) {
-	composePath := filepath.Join(localnetDir, composeSidecarFileName)
 
-	content, err := getSidecarComposeContent()
-	if err != nil {
-		return "", err
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, fileName+".tmpl", spec); err != nil {
+		return "", fmt.Errorf("failed to execute %s template: %w", fileName, err)
 	}
 
 	if err := os.MkdirAll(filepath.Dir(composePath), 0755); err != nil {
 		return "", fmt.Errorf("failed to create %s directory: %w", localnetDir, err)
 	}
 
-	if err := os.WriteFile(composePath, []byte(content), 0644); err != nil {
-		return "", fmt.Errorf("failed to write %s: %w", composeSidecarFileName, err)
+	if err := os.WriteFile(composePath, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", fileName, err)
 	}
 
 	return composePath, nil
 }
 
-// getSidecarComposeContent returns the embedded docker-compose.sidecar.yml content.
-func getSidecarComposeContent() (string, error) {
-	content, err := embeddedComposeFS.ReadFile(composeSidecarFileName)
-	if err != nil {
-		return "", fmt.Errorf("failed to read embedded %s: %w", composeSidecarFileName, err)
+// quoteEnvValue single-quotes values so compose does not interpolate them.
+func quoteEnvValue(value string) string {
+	if value == "" || strings.Contains(value, "'") {
+		return value
 	}
-	return string(content), nil
+	return "'" + value + "'"
 }
@@ -30,37 +30,24 @@ func NewEnvBuilder(rootDir, networksDir, servicesDir string) *EnvBuilder {
 }
 
 // BuildComposeEnv builds environment variables for docker-compose.
+// Per-chain settings (chain IDs, ports, images) are rendered into the compose files directly;
+// the environment only carries secrets, endpoints and host paths.
 // The gameFactoryAddr parameter can be empty (zero address) for dev deployments.
 func (b *EnvBuilder) BuildComposeEnv(cfg configs.L2, gameFactoryAddr common.Address) (map[string]string, error) {
 	env := make(map[string]string)
 
-	publisherPath, err := b.ResolveRepoPath(cfg.Repositories[configs.RepositoryNamePublisher], configs.RepositoryNamePublisher)
+	rootHost, err := path.GetHostPath(b.rootDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve publisher path: %w", err)
+		return nil, fmt.Errorf("failed to resolve host path for rootDir: %w", err)
 	}
 
-	opGethPath, err := b.ResolveRepoPath(cfg.Repositories[configs.RepositoryNameOpGeth], configs.RepositoryNameOpGeth)
+	localnetHost, err := path.GetHostPath(filepath.Join(b.rootDir, configs.WorkDir()))
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve op-geth path: %w", err)
-	}
-
-	rollupAConfigPath := filepath.Join(b.networksDir, string(configs.L2ChainNameRollupA))
-	rollupBConfigPath := filepath.Join(b.networksDir, string(configs.L2ChainNameRollupB))
-
-	rollupAHost, err := path.GetHostPath(rollupAConfigPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve host path for rollup-a config: %w", err)
-	}
-	rollupBHost, err := path.GetHostPath(rollupBConfigPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve host path for rollup-b config: %w", err)
-	}
-	rootHost, err := path.GetHostPath(b.rootDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve host path for rootDir: %w", err)
+		return nil, fmt.Errorf("failed to resolve host path for the work directory: %w", err)
 	}
 
 	env["ROOT_DIR"] = rootHost
+	env["LOCALNET_DIR"] = localnetHost
 	env["WALLET_PRIVATE_KEY"] = cfg.Wallet.PrivateKey
 	env["WALLET_ADDRESS"] = cfg.Wallet.Address
 	env["L1_EL_URL"] = cfg.L1ElURL
@@ -71,53 +58,41 @@ func (b *EnvBuilder) BuildComposeEnv(cfg configs.L2, gameFactoryAddr common.Addr
 	env["SEQUENCER_PRIVATE_KEY"] = cfg.CoordinatorPrivateKey
 	env["SP_L1_SUPERBLOCK_CONTRACT"] = ""
 
-	env["PUBLISHER_PATH"] = publisherPath
-	env["OP_GETH_PATH"] = opGethPath
-
-	env["ROLLUP_A_CHAIN_ID"] = fmt.Sprintf("%d", cfg.ChainConfigs[configs.L2ChainNameRollupA].ID)
-	env["ROLLUP_A_RPC_PORT"] = fmt.Sprintf("%d", cfg.ChainConfigs[configs.L2ChainNameRollupA].RPCPort)
-	env["ROLLUP_A_CONFIG_PATH"] = rollupAHost
-	env["ROLLUP_A_CONFIG_PATH_CONTAINER"] = rollupAConfigPath
+	// Components running a prebuilt image have no build context.
+	for _, name := range BuiltRepositories(cfg) {
+		repoPath, err := b.ResolveRepoPath(cfg.Repositories[name], name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s path: %w", name, err)
+		}
+		env[repositoryEnv(name, "PATH")] = repoPath
+	}
 
-	env["ROLLUP_B_CHAIN_ID"] = fmt.Sprintf("%d", cfg.ChainConfigs[configs.L2ChainNameRollupB].ID)
-	env["ROLLUP_B_RPC_PORT"] = fmt.Sprintf("%d", cfg.ChainConfigs[configs.L2ChainNameRollupB].RPCPort)
-	env["ROLLUP_B_CONFIG_PATH"] = rollupBHost
-	env["ROLLUP_B_CONFIG_PATH_CONTAINER"] = rollupBConfigPath
+	for _, chainName := range cfg.ChainNames() {
+		prefix := "ROLLUP_" + chainName.EnvSuffix() + "_"
 
-	env["FLASHBLOCKS_ROLLUP_A_RPC_PORT"] = fmt.Sprintf("%d", cfg.Flashblocks.RollupARPCPort)
-	env["FLASHBLOCKS_ROLLUP_B_RPC_PORT"] = fmt.Sprintf("%d", cfg.Flashblocks.RollupBRPCPort)
+		configPath := filepath.Join(b.networksDir, string(chainName))
+		configHostPath, err := path.GetHostPath(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve host path for %s config: %w", chainName, err)
+		}
 
-	env["SIDECAR_ROLLUP_A_API_PORT"] = fmt.Sprintf("%d", cfg.Sidecar.RollupAAPIPort)
-	env["SIDECAR_ROLLUP_B_API_PORT"] = fmt.Sprintf("%d", cfg.Sidecar.RollupBAPIPort)
+		env[prefix+"CONFIG_PATH"] = configHostPath
+		env[prefix+"CONFIG_PATH_CONTAINER"] = configPath
 
-	if cfg.Sidecar.Enabled {
-		sidecarPath, err := b.ResolveRepoPath(cfg.Repositories[configs.RepositoryNameSidecar], configs.RepositoryNameSidecar)
-		if err != nil {
-			return nil, fmt.Errorf("failed to resolve sidecar path: %w", err)
+		if mailbox := b.readMailboxAddress(chainName); mailbox != "" {
+			env["MAILBOX_"+chainName.EnvSuffix()] = mailbox
 		}
-		env["SIDECAR_PATH"] = sidecarPath
 	}
 
 	env["SP_L1_DISPUTE_GAME_FACTORY"] = gameFactoryAddr.Hex()
 
-	env["OP_BATCHER_IMAGE_TAG"] = cfg.Images[configs.ImageNameOpBatcher].Tag
-	env["OP_NODE_IMAGE_TAG"] = cfg.Images[configs.ImageNameOpNode].Tag
-	env["OP_PROPOSER_IMAGE_TAG"] = cfg.Images[configs.ImageNameOpProposer].Tag
-
-	if ma := b.readMailboxAddress(configs.L2ChainNameRollupA); ma != "" {
-		env["MAILBOX_A"] = ma
-	}
-	if mb := b.readMailboxAddress(configs.L2ChainNameRollupB); mb != "" {
-		env["MAILBOX_B"] = mb
-	}
-
 	return env, nil
 }
 
 // ResolveRepoPath resolves the repository path for a given repository configuration.
 // This is exported so other packages can resolve paths consistently.
 // Config validation ensures URL and local-path are mutually exclusive.
-// When URL is set, uses cloned repository path (.localnet/services/<name>).
+// When URL is set, uses cloned repository path (<work dir>/services/<name>).
 // When local-path is set, uses the specified local path (for development).
 // When running in Docker:
 //   - Cloned paths stay as container paths (accessible via workspace mount)
@@ -150,6 +125,11 @@ func (b *EnvBuilder) ResolveRepoPath(repo configs.Repository, name configs.Repos
 	return "", fmt.Errorf("repository %s has neither URL nor local-path set", name)
 }
 
+// repositoryEnv returns a compose variable of repository name, e.g. OP_GETH_PATH for the suffix PATH.
+func repositoryEnv(name configs.RepositoryName, suffix string) string {
+	return strings.ToUpper(strings.ReplaceAll(string(name), "-", "_")) + "_" + suffix
+}
+
 // readMailboxAddress reads the mailbox address from contracts.json for a given chain.
 // Returns empty string if file doesn't exist or address not found (best-effort).
 func (b *EnvBuilder) readMailboxAddress(chainName configs.L2ChainName) string {
@@ -0,0 +1,295 @@
+package docker
+
+import (
+	"fmt"
+
+	"github.com/compose-network/local-testnet/configs"
+	dockerclient "github.com/compose-network/local-testnet/internal/docker"
+	"github.com/compose-network/local-testnet/internal/ports"
+)
+
+const (
+	opStackImageRegistry = "us-docker.pkg.dev/oplabs-tools-artifacts/images"
+	rollupBoostImage     = "flashbots/rollup-boost"
+
+	publisherImage  = "local/publisher:dev"
+	opGethImage     = "local/op-geth:dev"
+	opRbuilderImage = "local/op-rbuilder:dev"
+	sidecarImage    = "local/sidecar:dev"
+)
+
+// LocalImages lists the images built from source for the L2 stack.
+var LocalImages = []string{publisherImage, opGethImage, opRbuilderImage, sidecarImage}
+
+// op-rbuilder is built from a git build context instead of a clone, overridable with OP_RBUILDER_PATH.
+// The lock file records its commit under OpRbuilderName.
+const (
+	OpRbuilderName       = "op-rbuilder"
+	OpRbuilderRepository = "https://github.com/compose-network/op-rbuilder.git"
+	OpRbuilderRef        = "stage"
+)
+
+type (
+	// ServiceSpec holds the config-driven part of a compose service definition.
+	// Static parts (commands, healthchecks, secrets references) live in the templates.
+	ServiceSpec struct {
+		Name       string
+		Image      string
+		Ports      []dockerclient.PortBinding
+		DataVolume string
+		// BuildFrom names the source a locally built image is built from, empty for pulled images.
+		BuildFrom string
+	}
+
+	// ChainSpec describes every service of a single rollup.
+	ChainSpec struct {
+		Name        configs.L2ChainName
+		Suffix      string
+		EnvSuffix   string
+		ChainID     int
+		OpGeth      ServiceSpec
+		OpNode      ServiceSpec
+		OpBatcher   ServiceSpec
+		OpProposer  ServiceSpec
+		OpRbuilder  ServiceSpec
+		RollupBoost ServiceSpec
+		Sidecar     ServiceSpec
+		Peers       []ChainSpec
+	}
+
+	// ComposeSpec is the typed model all L2 compose templates are rendered from.
+	ComposeSpec struct {
+		// Project, Stack and Network name the compose project, the stack label and the network of the selected instance.
+		Project   string
+		Stack     string
+		Network   string
+		Publisher ServiceSpec
+		Chains    []ChainSpec
+		// BatcherDataAvailability is the data availability type the batchers post with, empty for the op-batcher default.
+		BatcherDataAvailability string
+	}
+)
+
+// NewComposeSpec builds the compose model for the configured chains.
+func NewComposeSpec(cfg configs.L2) ComposeSpec {
+	spec := ComposeSpec{
+		Project:                 dockerclient.ComposeProject(),
+		Stack:                   dockerclient.L2Stack(),
+		Network:                 dockerclient.L2Network(),
+		BatcherDataAvailability: batcherDataAvailability(),
+		Publisher: ServiceSpec{
+			Name:      PublisherService,
+			Image:     sourceImage(cfg, configs.RepositoryNamePublisher, publisherImage),
+			BuildFrom: sourceBuildFrom(cfg, configs.RepositoryNamePublisher),
+			Ports: []dockerclient.PortBinding{
+				hostPort(cfg, PublisherService, 8080, configs.PublisherAPIPort, ""),
+				hostPort(cfg, PublisherService, 8081, configs.PublisherMetricsPort, ""),
+			},
+		},
+	}
+
+	for _, name := range cfg.ChainNames() {
+		spec.Chains = append(spec.Chains, newChainSpec(cfg, name))
+	}
+
+	for i := range spec.Chains {
+		for j, peer := range spec.Chains {
+			if i != j {
+				spec.Chains[i].Peers = append(spec.Chains[i].Peers, peer)
+			}
+		}
+	}
+
+	return spec
+}
+
+// ContainerName returns the container name of the service in the selected instance.
+// The service name stays unprefixed, it is how services reach each other on the instance's network.
+func (s ServiceSpec) ContainerName() string {
+	return configs.ScopedName(s.Name)
+}
+
+// Volumes returns the named volumes declared by the given services.
+func Volumes(services ...ServiceSpec) []string {
+	volumes := make([]string, 0, len(services))
+	for _, service := range services {
+		if service.DataVolume != "" {
+			volumes = append(volumes, service.DataVolume)
+		}
+	}
+	return volumes
+}
+
+func newChainSpec(cfg configs.L2, name configs.L2ChainName) ChainSpec {
+	chain := cfg.ChainConfigs[name]
+	base := cfg.HostPortBase(name)
+
+	opGeth := OpGethService(name)
+	opRbuilder := OpRbuilderService(name)
+	rollupBoost := RollupBoostService(name)
+
+	return ChainSpec{
+		Name:      name,
+		Suffix:    name.Suffix(),
+		EnvSuffix: name.EnvSuffix(),
+		ChainID:   chain.ID,
+		OpGeth: ServiceSpec{
+			Name:      opGeth,
+			Image:     sourceImage(cfg, configs.RepositoryNameOpGeth, opGethImage),
+			BuildFrom: sourceBuildFrom(cfg, configs.RepositoryNameOpGeth),
+			Ports: []dockerclient.PortBinding{
+				hostPort(cfg, opGeth, 8545, chain.RPCPort, ""),
+				hostPort(cfg, opGeth, 8546, base+configs.OpGethWSPortOffset, ""),
+				hostPort(cfg, opGeth, 8551, base+configs.OpGethAuthRPCPortOffset, ""),
+				hostPort(cfg, opGeth, 9898, base+configs.OpGethSequencerPortOffset, ""),
+			},
+			DataVolume: fmt.Sprintf("%s-geth", name),
+		},
+		OpNode: ServiceSpec{
+			Name:       OpNodeService(name),
+			Image:      opStackImage(cfg, configs.ImageNameOpNode),
+			Ports:      []dockerclient.PortBinding{hostPort(cfg, OpNodeService(name), 9545, base+configs.OpNodeRPCPortOffset, "")},
+			DataVolume: fmt.Sprintf("%s-opnode", name),
+		},
+		OpBatcher: ServiceSpec{
+			Name:  OpBatcherService(name),
+			Image: opStackImage(cfg, configs.ImageNameOpBatcher),
+			Ports: []dockerclient.PortBinding{hostPort(cfg, OpBatcherService(name), 8548, base+configs.OpBatcherRPCPortOffset, "")},
+		},
+		OpProposer: ServiceSpec{
+			Name:  OpProposerService(name),
+			Image: opStackImage(cfg, configs.ImageNameOpProposer),
+			Ports: []dockerclient.PortBinding{hostPort(cfg, OpProposerService(name), 8560, base+configs.OpProposerRPCPortOffset, "")},
+		},
+		OpRbuilder: ServiceSpec{
+			Name:      opRbuilder,
+			Image:     opRbuilderImage,
+			BuildFrom: opRbuilderBuildFrom(cfg),
+			Ports: []dockerclient.PortBinding{
+				hostPort(cfg, opRbuilder, 8551, base+configs.OpRbuilderAuthRPCPortOffset, "Engine API"),
+				hostPort(cfg, opRbuilder, 8545, chain.FlashblocksRPCPort, "HTTP RPC"),
+				hostPort(cfg, opRbuilder, 1111, base+configs.OpRbuilderWSPortOffset, "Flashblocks WS"),
+				hostPort(cfg, opRbuilder, 9001, base+configs.OpRbuilderMetricsPortOffset, "Metrics"),
+			},
+			DataVolume: fmt.Sprintf("op-rbuilder-%s-data", name.Suffix()),
+		},
+		RollupBoost: ServiceSpec{
+			Name:  rollupBoost,
+			Image: cfg.Lock.Image(fmt.Sprintf("%s:%s", rollupBoostImage, imageTagOrLatest(cfg.Flashblocks.RollupBoostImageTag))),
+			Ports: []dockerclient.PortBinding{
+				hostPort(cfg, rollupBoost, 8551, base+configs.RollupBoostAuthRPCPortOffset, "Engine API (op-node connects here)"),
+				hostPort(cfg, rollupBoost, 5555, base+configs.RollupBoostDebugPortOffset, "Debug API"),
+				hostPort(cfg, rollupBoost, 9999, base+configs.RollupBoostSSEPortOffset, "Flashblocks SSE"),
+			},
+		},
+		Sidecar: ServiceSpec{
+			Name:      SidecarService(name),
+			Image:     sourceImage(cfg, configs.RepositoryNameSidecar, sidecarImage),
+			BuildFrom: sourceBuildFrom(cfg, configs.RepositoryNameSidecar),
+			Ports:     []dockerclient.PortBinding{hostPort(cfg, SidecarService(name), 8090, chain.SidecarAPIPort, "")},
+		},
+	}
+}
+
+// hostPort binds a container port to its host port from the resolved port map, or to def.
+func hostPort(cfg configs.L2, service string, containerPort, def int, comment string) dockerclient.PortBinding {
+	name := ports.Name(service, containerPort)
+	return dockerclient.PortBinding{Name: name, Host: cfg.HostPorts.Get(name, def), Container: containerPort, Comment: comment}
+}
+
+// Services lists the L2 compose services enabled by the configuration.
+func Services(cfg configs.L2) []ServiceSpec {
+	spec := NewComposeSpec(cfg)
+
+	services := []ServiceSpec{spec.Publisher}
+	for _, chain := range spec.Chains {
+		services = append(services, chain.OpGeth, chain.OpNode, chain.OpBatcher, chain.OpProposer)
+		if cfg.Flashblocks.Enabled {
+			services = append(services, chain.OpRbuilder, chain.RollupBoost)
+		}
+		if cfg.Sidecar.Enabled {
+			services = append(services, chain.Sidecar)
+		}
+	}
+	return services
+}
+
+// HostPorts lists the host ports published by the enabled L2 compose services, with their defaults.
+func HostPorts(cfg configs.L2) []ports.Port {
+	cfg.HostPorts = nil
+
+	var hostPorts []ports.Port
+	for _, service := range Services(cfg) {
+		for _, binding := range service.Ports {
+			hostPorts = append(hostPorts, ports.Port{Name: binding.Name, Default: binding.Host})
+		}
+	}
+	return hostPorts
+}
+
+// ApplyHostPorts stores the resolved port map in the configuration and updates the configured
+// chain ports, which host-side consumers (contract deployment, registry, output) read directly.
+func ApplyHostPorts(cfg *configs.L2, hostPorts ports.Map) {
+	cfg.HostPorts = hostPorts
+
+	chains := make(map[configs.L2ChainName]configs.Chain, len(cfg.ChainConfigs))
+	for name, chain := range cfg.ChainConfigs {
+		chain.RPCPort = hostPorts.Get(ports.Name(OpGethService(name), 8545), chain.RPCPort)
+		chain.FlashblocksRPCPort = hostPorts.Get(ports.Name(OpRbuilderService(name), 8545), chain.FlashblocksRPCPort)
+		chain.SidecarAPIPort = hostPorts.Get(ports.Name(SidecarService(name), 8090), chain.SidecarAPIPort)
+		chains[name] = chain
+	}
+	cfg.ChainConfigs = chains
+}
+
+// batcherDataAvailability returns the data availability type of the batchers. The mock beacon API of the
+// dev L1 backend serves no blobs, so op-node could not derive batches posted as blobs: its batchers post calldata.
+func batcherDataAvailability() string {
+	if configs.Values.L1.BackendOrDefault() == configs.L1BackendDev {
+		return "calldata"
+	}
+	return ""
+}
+
+// opStackImage returns the configured image of an OP Stack component, or its digest pinned by the lock file.
+func opStackImage(cfg configs.L2, name configs.ImageName) string {
+	return cfg.Lock.Image(fmt.Sprintf("%s/%s:%s", opStackImageRegistry, name, cfg.Images[name].Tag))
+}
+
+// OpGethImage returns the image op-geth runs from, see sourceImage.
+func OpGethImage(cfg configs.L2) string {
+	return sourceImage(cfg, configs.RepositoryNameOpGeth, opGethImage)
+}
+
+// sourceImage returns the image of a component built from repository name: its prebuilt image, or its digest
+// pinned by the lock file, when one is configured, localImage otherwise.
+func sourceImage(cfg configs.L2, name configs.RepositoryName, localImage string) string {
+	if prebuilt := cfg.PrebuiltImage(name); prebuilt != "" {
+		return cfg.Lock.Image(prebuilt)
+	}
+	return localImage
+}
+
+// sourceBuildFrom returns the repository a component's image is built from, empty when it runs a prebuilt image.
+func sourceBuildFrom(cfg configs.L2, name configs.RepositoryName) string {
+	if cfg.PrebuiltImage(name) != "" {
+		return ""
+	}
+	return string(name)
+}
+
+// opRbuilderBuildFrom returns the git build context of op-rbuilder, at the commit pinned by the lock file if any.
+func opRbuilderBuildFrom(cfg configs.L2) string {
+	ref := OpRbuilderRef
+	if pinned, ok := cfg.Lock.Repositories[OpRbuilderName]; ok {
+		ref = pinned.Commit
+	}
+	return OpRbuilderRepository + "#" + ref
+}
+
+func imageTagOrLatest(tag string) string {
+	if tag == "" {
+		return "latest"
+	}
+	return tag
+}
@@ -0,0 +1,59 @@
+package docker
+
+import "github.com/compose-network/local-testnet/configs"
+
+// PublisherService is the compose service name of the shared publisher.
+const PublisherService = "publisher"
+
+// OpGethService returns the compose service name of the chain's execution client.
+func OpGethService(chain configs.L2ChainName) string {
+	return "op-geth-" + chain.Suffix()
+}
+
+// OpNodeService returns the compose service name of the chain's consensus client.
+func OpNodeService(chain configs.L2ChainName) string {
+	return "op-node-" + chain.Suffix()
+}
+
+// OpBatcherService returns the compose service name of the chain's batcher.
+func OpBatcherService(chain configs.L2ChainName) string {
+	return "op-batcher-" + chain.Suffix()
+}
+
+// OpProposerService returns the compose service name of the chain's proposer.
+func OpProposerService(chain configs.L2ChainName) string {
+	return "op-proposer-" + chain.Suffix()
+}
+
+// OpRbuilderService returns the compose service name of the chain's flashblocks builder.
+func OpRbuilderService(chain configs.L2ChainName) string {
+	return "op-rbuilder-" + chain.Suffix()
+}
+
+// RollupBoostService returns the compose service name of the chain's engine API multiplexer.
+func RollupBoostService(chain configs.L2ChainName) string {
+	return "rollup-boost-" + chain.Suffix()
+}
+
+// SidecarService returns the compose service name of the chain's sidecar.
+func SidecarService(chain configs.L2ChainName) string {
+	return "sidecar-" + chain.Suffix()
+}
+
+// OpGethServices returns the op-geth service names for all given chains.
+func OpGethServices(chains []configs.L2ChainName) []string {
+	return mapChains(chains, OpGethService)
+}
+
+// SidecarServices returns the sidecar service names for all given chains.
+func SidecarServices(chains []configs.L2ChainName) []string {
+	return mapChains(chains, SidecarService)
+}
+
+func mapChains(chains []configs.L2ChainName, fn func(configs.L2ChainName) string) []string {
+	services := make([]string, 0, len(chains))
+	for _, chain := range chains {
+		services = append(services, fn(chain))
+	}
+	return services
+}
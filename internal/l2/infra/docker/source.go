@@ -0,0 +1,60 @@
+package docker
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/compose-network/local-testnet/configs"
+	dockerclient "github.com/compose-network/local-testnet/internal/docker"
+	"github.com/compose-network/local-testnet/internal/l2/infra/git"
+)
+
+// SourceRevisionEnv returns the compose variable the image built from repository name reads its dockerclient.SourceLabel
+// from, e.g. OP_GETH_SOURCE_REVISION.
+func SourceRevisionEnv(name configs.RepositoryName) string {
+	return repositoryEnv(name, "SOURCE_REVISION")
+}
+
+// sourceLabel renders the build label of the image built from repository name, empty when the variable is unset.
+func sourceLabel(name string) string {
+	return dockerclient.SourceLabel + "=${" + SourceRevisionEnv(configs.RepositoryName(name)) + ":-}"
+}
+
+// SourceRevision resolves the revision of the checkout the image of repository name is built from. It is
+// empty when the checkout cannot be read, e.g. a local path outside of the container, images built from
+// such a checkout are always built.
+func (b *EnvBuilder) SourceRevision(ctx context.Context, cfg configs.L2, name configs.RepositoryName) string {
+	repoPath, err := b.ResolveRepoPath(cfg.Repositories[name], name)
+	if err != nil {
+		slog.With("name", name, "err", err.Error()).Warn("failed to resolve source of image, it is always built")
+		return ""
+	}
+	revision, err := git.NewCloner().SourceRevision(ctx, repoPath)
+	if err != nil {
+		slog.With("name", name, "path", repoPath, "err", err.Error()).Warn("failed to resolve source revision of image, it is always built")
+		return ""
+	}
+	return revision
+}
+
+// AddSourceRevisions adds the SourceRevisionEnv variable of every enabled image built from a configured
+// repository to env.
+func (b *EnvBuilder) AddSourceRevisions(ctx context.Context, cfg configs.L2, env map[string]string) {
+	for _, name := range BuiltRepositories(cfg) {
+		env[SourceRevisionEnv(name)] = b.SourceRevision(ctx, cfg, name)
+	}
+}
+
+// BuiltRepositories lists the configured repositories enabled services build their image from.
+// op-rbuilder is built from its remote by docker compose up and is not one of them.
+func BuiltRepositories(cfg configs.L2) []configs.RepositoryName {
+	var names []configs.RepositoryName
+	for _, service := range Services(cfg) {
+		name := configs.RepositoryName(service.BuildFrom)
+		if _, ok := cfg.Repositories[name]; ok && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
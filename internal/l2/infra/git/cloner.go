@@ -1,26 +1,38 @@
 package git
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
 
 	"github.com/compose-network/local-testnet/internal/logger"
+	"golang.org/x/sync/errgroup"
 )
 
+// commitPattern matches abbreviated and full commit SHAs. Such refs may not be fetchable by name,
+// see fetch.
+var commitPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
 // Repository represents a git repository to clone
 type Repository struct {
 	Name string
 	URL  string
-	Ref  string // branch, tag, or commit
+	Ref  string // branch, tag, or commit; empty means the remote's default branch
 }
 
 // Cloner handles git repository operations
 type Cloner struct {
-	logger *slog.Logger
+	offline bool
+	logger  *slog.Logger
 }
 
 // NewCloner creates a new git cloner
@@ -30,14 +42,43 @@ func NewCloner() *Cloner {
 	}
 }
 
-// CloneAll clones multiple repositories in parallel
+// WithOffline makes the cloner use existing clones as they are, without contacting the remotes.
+func (c *Cloner) WithOffline() *Cloner {
+	c.offline = true
+	return c
+}
+
+// maxParallelClones bounds the clones CloneAll runs at once, to go easy on the network and the remotes.
+const maxParallelClones = 4
+
+// CloneAll clones multiple repositories in parallel. The first failure cancels the clones still running
+// and is returned, clones already completed stay in place.
 func (c *Cloner) CloneAll(ctx context.Context, destDir string, repos []Repository) error {
-	c.logger.Info("cloning repositories", "count", len(repos), "destination", destDir)
+	c.logger.Info("cloning repositories", "count", len(repos), "destination", destDir, "parallel", maxParallelClones)
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(maxParallelClones)
 
+	var completed atomic.Int32
 	for _, repo := range repos {
-		if err := c.Clone(ctx, destDir, repo); err != nil {
-			return fmt.Errorf("failed to clone %s: %w", repo.Name, err)
-		}
+		group.Go(func() error {
+			// Clones still queued when another one failed are not started.
+			if err := groupCtx.Err(); err != nil {
+				return err
+			}
+			if err := c.Clone(groupCtx, destDir, repo); err != nil {
+				if groupCtx.Err() != nil && ctx.Err() == nil {
+					c.logger.With("name", repo.Name).Info("clone canceled after another clone failed")
+				}
+				return fmt.Errorf("failed to clone %s: %w", repo.Name, err)
+			}
+			c.logger.With("name", repo.Name, "progress", fmt.Sprintf("%d/%d", completed.Add(1), len(repos))).
+				Info("repository ready")
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return err
 	}
 
 	c.logger.Info("all repositories cloned successfully")
@@ -45,31 +86,283 @@ func (c *Cloner) CloneAll(ctx context.Context, destDir string, repos []Repositor
 	return nil
 }
 
-// Clone clones a single repository
+// Clone checks out repo.Ref in destDir/repo.Name. A new directory is initialized and fetched, an existing
+// clone is fetched and reset to the ref, so a changed ref in the configuration always takes effect.
+// Local modifications of tracked files in an existing clone are discarded with a warning.
 func (c *Cloner) Clone(ctx context.Context, destDir string, repo Repository) error {
-	logger := c.logger.With("name", repo.Name).With("url", repo.URL)
+	repoLogger := c.logger.With("name", repo.Name).With("url", repo.URL).With("ref", repo.Ref)
 	repoPath := filepath.Join(destDir, repo.Name)
 
+	if c.offline {
+		return c.checkoutOffline(ctx, repoLogger, repoPath, repo)
+	}
+
 	if _, err := os.Stat(filepath.Join(repoPath, ".git")); err == nil {
-		logger.Info("repository already cloned, skipping")
-		return nil
+		repoLogger.Info("repository already cloned, updating to the configured ref")
+		if err := c.prepareExisting(ctx, repoLogger, repoPath, repo); err != nil {
+			return err
+		}
+	} else {
+		repoLogger.Info("cloning repository")
+		if err := os.MkdirAll(repoPath, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := c.git(ctx, repoPath, "init", "--quiet"); err != nil {
+			return fmt.Errorf("git init failed: %w", err)
+		}
+		if err := c.git(ctx, repoPath, "remote", "add", "origin", repo.URL); err != nil {
+			return fmt.Errorf("git remote add failed: %w", err)
+		}
+	}
+
+	target, err := c.fetch(ctx, repoLogger, repoPath, repo.Ref)
+	if err != nil {
+		return err
+	}
+
+	if err := c.git(ctx, repoPath, "checkout", "--quiet", "--force", "--detach", target); err != nil {
+		return fmt.Errorf("git checkout of '%s' failed: %w", repo.Ref, err)
+	}
+
+	commit, err := c.gitOutput(ctx, repoPath, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to resolve checked out commit: %w", err)
+	}
+
+	repoLogger.With("commit", commit).Info("repository checked out")
+
+	return nil
+}
+
+// checkoutOffline checks out a commit ref that is available in the existing clone. Other refs cannot be
+// resolved without the remote, so the clone stays at the commit it has checked out.
+func (c *Cloner) checkoutOffline(ctx context.Context, repoLogger *slog.Logger, repoPath string, repo Repository) error {
+	if _, err := os.Stat(filepath.Join(repoPath, ".git")); err != nil {
+		return fmt.Errorf("repository is not cloned in %s and cannot be cloned offline. Import a bundle with it first", repoPath)
 	}
 
-	c.logger.Info("cloning repository")
+	if commitPattern.MatchString(repo.Ref) {
+		commit, err := c.gitOutput(ctx, repoPath, "rev-parse", "--verify", "--quiet", repo.Ref+"^{commit}")
+		if err != nil {
+			return fmt.Errorf("commit '%s' is not available offline in %s", repo.Ref, repoPath)
+		}
+		if err := c.git(ctx, repoPath, "checkout", "--quiet", "--force", "--detach", commit); err != nil {
+			return fmt.Errorf("git checkout of '%s' failed: %w", repo.Ref, err)
+		}
+	}
 
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+	commit, err := c.gitOutput(ctx, repoPath, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to resolve checked out commit: %w", err)
 	}
 
-	cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", "--branch", repo.Ref, repo.URL, repoPath)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	repoLogger.With("commit", commit).Info("offline, using existing clone")
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git clone failed: %w", err)
+	return nil
+}
+
+// prepareExisting points an existing clone at repo.URL and reports local modifications,
+// which the following checkout discards.
+func (c *Cloner) prepareExisting(ctx context.Context, repoLogger *slog.Logger, repoPath string, repo Repository) error {
+	remoteURL, err := c.gitOutput(ctx, repoPath, "remote", "get-url", "origin")
+	switch {
+	case err != nil:
+		if err := c.git(ctx, repoPath, "remote", "add", "origin", repo.URL); err != nil {
+			return fmt.Errorf("git remote add failed: %w", err)
+		}
+	case remoteURL != repo.URL:
+		repoLogger.With("previous_url", remoteURL).Warn("repository URL changed, updating origin")
+		if err := c.git(ctx, repoPath, "remote", "set-url", "origin", repo.URL); err != nil {
+			return fmt.Errorf("git remote set-url failed: %w", err)
+		}
 	}
 
-	c.logger.Info("repository cloned successfully")
+	// Untracked files are build outputs and generated configs, only changes to tracked files are drift.
+	status, err := c.gitOutput(ctx, repoPath, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return fmt.Errorf("git status failed: %w", err)
+	}
+	if status != "" {
+		var modified []string
+		for _, line := range strings.Split(status, "\n") {
+			if len(line) > 3 {
+				modified = append(modified, line[3:])
+			}
+		}
+		repoLogger.
+			With("modified", modified).
+			Warn("repository has local modifications, they are discarded. Use local-path to develop against your own checkout")
+	}
 
 	return nil
 }
+
+// fetch fetches ref from origin and returns what to check out. Branches, tags and full commit SHAs are
+// fetched shallowly by name. Servers refuse to fetch abbreviated SHAs, so for those the whole history
+// is fetched and the SHA is resolved locally.
+func (c *Cloner) fetch(ctx context.Context, repoLogger *slog.Logger, repoPath, ref string) (string, error) {
+	name := ref
+	if name == "" {
+		name = "HEAD"
+	}
+
+	err := c.git(ctx, repoPath, "fetch", "--quiet", "--depth", "1", "--force", "origin", name)
+	if err == nil {
+		return "FETCH_HEAD", nil
+	}
+	if !commitPattern.MatchString(ref) {
+		return "", fmt.Errorf("git fetch of '%s' failed: %w", name, err)
+	}
+
+	repoLogger.Info("ref looks like an abbreviated commit, fetching full history to resolve it")
+	args := []string{"fetch", "--quiet", "--force", "--tags", "origin", "+refs/heads/*:refs/remotes/origin/*"}
+	if _, statErr := os.Stat(filepath.Join(repoPath, ".git", "shallow")); statErr == nil {
+		args = append(args, "--unshallow")
+	}
+	if err := c.git(ctx, repoPath, args...); err != nil {
+		return "", fmt.Errorf("git fetch of full history failed: %w", err)
+	}
+
+	commit, err := c.gitOutput(ctx, repoPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("ref '%s' is neither a branch, a tag nor a commit of %s", ref, repoPath)
+	}
+	return commit, nil
+}
+
+// Commit returns the commit checked out in the repository at repoPath.
+func (c *Cloner) Commit(ctx context.Context, repoPath string) (string, error) {
+	commit, err := c.gitOutput(ctx, repoPath, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve commit of %s: %w", repoPath, err)
+	}
+	return commit, nil
+}
+
+// SourceRevision identifies the source checked out at repoPath: the commit, followed by "-dirty-" and a hash
+// of the uncommitted changes and untracked files if there are any, so every edit yields a new revision.
+func (c *Cloner) SourceRevision(ctx context.Context, repoPath string) (string, error) {
+	commit, err := c.Commit(ctx, repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	diff, err := c.gitOutput(ctx, repoPath, "diff", "--binary", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("git diff failed in %s: %w", repoPath, err)
+	}
+	untracked, err := c.gitOutput(ctx, repoPath, "ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return "", fmt.Errorf("git ls-files failed in %s: %w", repoPath, err)
+	}
+	if diff == "" && untracked == "" {
+		return commit, nil
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(diff))
+	for _, name := range strings.Split(untracked, "\x00") {
+		if name == "" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(repoPath, name))
+		if err != nil {
+			return "", fmt.Errorf("failed to read untracked file %s: %w", name, err)
+		}
+		fmt.Fprintf(hash, "\x00%s\x00%d\x00", name, len(content))
+		hash.Write(content)
+	}
+
+	return commit + "-dirty-" + hex.EncodeToString(hash.Sum(nil))[:12], nil
+}
+
+// RemoteCommit resolves ref (a branch or a tag, empty for the default branch) of the remote repository at url
+// without cloning it. A full commit SHA is returned as is.
+func (c *Cloner) RemoteCommit(ctx context.Context, url, ref string) (string, error) {
+	if len(ref) == 40 && commitPattern.MatchString(ref) {
+		return ref, nil
+	}
+
+	if ref == "" {
+		refs, err := c.remoteRefs(ctx, url, "HEAD")
+		if err != nil {
+			return "", err
+		}
+		if refs["HEAD"] == "" {
+			return "", fmt.Errorf("default branch not found in %s", url)
+		}
+		return refs["HEAD"], nil
+	}
+
+	// Branches and tags are queried by their full name, a bare pattern would also match refs ending in ref.
+	// Annotated tags are listed twice, the peeled "<tag>^{}" line holds the commit they point to.
+	branch, tag := "refs/heads/"+ref, "refs/tags/"+ref
+	refs, err := c.remoteRefs(ctx, url, branch, tag, tag+"^{}")
+	if err != nil {
+		return "", err
+	}
+	tagCommit := refs[tag+"^{}"]
+	if tagCommit == "" {
+		tagCommit = refs[tag]
+	}
+
+	switch {
+	case refs[branch] != "" && tagCommit != "":
+		return "", fmt.Errorf("ref '%s' is both a branch and a tag in %s", ref, url)
+	case refs[branch] != "":
+		return refs[branch], nil
+	case tagCommit != "":
+		return tagCommit, nil
+	default:
+		return "", fmt.Errorf("ref '%s' not found in %s", ref, url)
+	}
+}
+
+// remoteRefs lists the refs of the remote repository at url matching patterns, keyed by ref name.
+func (c *Cloner) remoteRefs(ctx context.Context, url string, patterns ...string) (map[string]string, error) {
+	output, err := c.gitOutput(ctx, "", append([]string{"ls-remote", url}, patterns...)...)
+	if err != nil {
+		return nil, fmt.Errorf("git ls-remote of %s failed: %w", url, err)
+	}
+
+	refs := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		if sha, name, ok := strings.Cut(line, "\t"); ok {
+			refs[name] = sha
+		}
+	}
+	return refs, nil
+}
+
+// git runs a git command in the clone at dir, streaming its output to the command logger.
+// Repositories are cloned concurrently, so the output is tagged with the name of the clone.
+func (c *Cloner) git(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	stdout, stderr := logger.CommandOutput("git")
+	stdout.With("name", filepath.Base(dir))
+	stderr.With("name", filepath.Base(dir))
+	defer stdout.Close()
+	defer stderr.Close()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	return cmd.Run()
+}
+
+// gitOutput runs a git command in dir and returns its trimmed standard output.
+func (c *Cloner) gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
@@ -10,7 +10,7 @@ import (
 	"strings"
 
 	"github.com/compose-network/local-testnet/configs"
-	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
+	"github.com/compose-network/local-testnet/internal/docker"
 	"github.com/compose-network/local-testnet/internal/l2/path"
 	"github.com/compose-network/local-testnet/internal/logger"
 )
@@ -30,18 +30,23 @@ type Deployer struct {
 }
 
 // NewDeployer creates a new op-deployer wrapper
-// imageTag should be the version tag (e.g., "v0.3.3")
-func NewDeployer(rootDir, stateDir, imageTag string, dockerClient *docker.Client) *Deployer {
+// image should be the op-deployer image reference, see Image
+func NewDeployer(rootDir, stateDir, image string, dockerClient *docker.Client) *Deployer {
 	return &Deployer{
 		rootDir:         rootDir,
 		stateDir:        stateDir,
-		imageWithTag:    fmt.Sprintf("%s:%s", publicImageName, imageTag),
+		imageWithTag:    image,
 		imageEntrypoint: "/usr/local/bin/op-deployer",
 		docker:          dockerClient,
 		logger:          logger.Named("deployer"),
 	}
 }
 
+// Image returns the configured op-deployer image, or its digest pinned by the lock file.
+func Image(cfg configs.L2) string {
+	return cfg.Lock.Image(fmt.Sprintf("%s:%s", publicImageName, cfg.Images[configs.ImageNameOpDeployer].Tag))
+}
+
 // Init initializes the op-deployer state
 func (o *Deployer) Init(ctx context.Context, l1ChainID int, l2Chains map[configs.L2ChainName]configs.Chain) error {
 	o.logger.
@@ -154,6 +159,7 @@ func (o *Deployer) Apply(ctx context.Context, l1RpcURL, deployerPrivateKey, depl
 		Volumes: map[string]string{
 			absStateDir: "/work",
 		},
+		ExtraHosts: []string{"host.docker.internal:host-gateway"},
 		WorkDir:    "/work",
 		User:       fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
 		AutoRemove: true,
@@ -86,6 +86,11 @@ func (s *Service) Deploy(ctx context.Context) (common.Address, error) {
 
 // generateNetworksToml creates networks.toml from template and config
 func (s *Service) generateNetworksToml() error {
+	return WriteNetworksToml(filepath.Join(s.contractsDir, "networks.toml"), s.cfg)
+}
+
+// WriteNetworksToml renders the networks.toml the compose-contracts deployment scripts read to outputPath.
+func WriteNetworksToml(outputPath string, cfg configs.L2) error {
 	tmplContent, err := templatesFS.ReadFile("networks.tmpl")
 	if err != nil {
 		return fmt.Errorf("failed to read template file: %w", err)
@@ -113,22 +118,21 @@ func (s *Service) generateNetworksToml() error {
 	}
 
 	data := templateData{
-		NetworkName:                     s.cfg.Dispute.NetworkName,
-		RpcURL:                          s.cfg.L1ElURL,
-		ChainID:                         s.cfg.L1ChainID,
-		ExplorerURL:                     s.cfg.Dispute.ExplorerURL,
-		ExplorerAPIURL:                  s.cfg.Dispute.ExplorerAPIURL,
-		VerifierAddress:                 s.cfg.Dispute.VerifierAddress,
-		OwnerAddress:                    s.cfg.Dispute.OwnerAddress,
-		ProposerAddress:                 s.cfg.Dispute.ProposerAddress,
-		AggregationVkey:                 s.cfg.Dispute.AggregationVkey,
-		GuardianAddress:                 s.cfg.Dispute.GuardianAddress,
-		ProofMaturityDelaySeconds:       s.cfg.Dispute.ProofMaturityDelaySeconds,
-		DisputeGameFinalityDelaySeconds: s.cfg.Dispute.DisputeGameFinalityDelaySeconds,
-		DisputeGameInitBond:             s.cfg.Dispute.DisputeGameInitBond,
-	}
-
-	outputPath := filepath.Join(s.contractsDir, "networks.toml")
+		NetworkName:                     cfg.Dispute.NetworkName,
+		RpcURL:                          cfg.L1ElURL,
+		ChainID:                         cfg.L1ChainID,
+		ExplorerURL:                     cfg.Dispute.ExplorerURL,
+		ExplorerAPIURL:                  cfg.Dispute.ExplorerAPIURL,
+		VerifierAddress:                 cfg.Dispute.VerifierAddress,
+		OwnerAddress:                    cfg.Dispute.OwnerAddress,
+		ProposerAddress:                 cfg.Dispute.ProposerAddress,
+		AggregationVkey:                 cfg.Dispute.AggregationVkey,
+		GuardianAddress:                 cfg.Dispute.GuardianAddress,
+		ProofMaturityDelaySeconds:       cfg.Dispute.ProofMaturityDelaySeconds,
+		DisputeGameFinalityDelaySeconds: cfg.Dispute.DisputeGameFinalityDelaySeconds,
+		DisputeGameInitBond:             cfg.Dispute.DisputeGameInitBond,
+	}
+
 	file, err := os.Create(outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to create networks.toml: %w", err)
@@ -182,8 +186,11 @@ func (s *Service) generateEnvFile() error {
 func (s *Service) runJustCommand(ctx context.Context, args ...string) error {
 	cmd := exec.CommandContext(ctx, "just", args...)
 	cmd.Dir = s.contractsDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	stdout, stderr := logger.CommandOutput("just")
+	defer stdout.Close()
+	defer stderr.Close()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	s.logger.
 		With("command", fmt.Sprintf("just %s", strings.Join(args, " "))).
@@ -7,7 +7,7 @@ import (
 	"strconv"
 
 	"github.com/compose-network/local-testnet/configs"
-	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
+	"github.com/compose-network/local-testnet/internal/docker"
 	"github.com/compose-network/local-testnet/internal/l2/infra/filesystem/json"
 	"github.com/compose-network/local-testnet/internal/l2/l1deployment/deployer"
 	"github.com/compose-network/local-testnet/internal/l2/l1deployment/dispute"
@@ -74,7 +74,7 @@ func (o *Orchestrator) Execute(ctx context.Context, cfg configs.L2) (DeploymentS
 	defer dockerClient.Close()
 
 	o.logger.Info("instantiating Deployer")
-	opDeployer := deployer.NewDeployer(o.rootDir, o.stateDir, cfg.Images[configs.ImageNameOpDeployer].Tag, dockerClient)
+	opDeployer := deployer.NewDeployer(o.rootDir, o.stateDir, deployer.Image(cfg), dockerClient)
 
 	o.logger.Info("initializing Deployer")
 	if err := opDeployer.Init(ctx, cfg.L1ChainID, cfg.ChainConfigs); err != nil {
@@ -0,0 +1,150 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/logger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Conservative estimates of the gas used by the phase 1 transactions. op-deployer deploys the superchain and
+// implementation contracts once and an OP chain per rollup; the dispute deployment is a forge script in
+// compose-contracts.
+const (
+	opDeployerBaseGas  = 40_000_000
+	opDeployerChainGas = 12_000_000
+	disputeGas         = 15_000_000
+
+	// marginPercent is added to the gas cost to cover gas price moves during the deployment.
+	marginPercent = 50
+)
+
+// devnetChainIDs are the L1 chain IDs of local devnets, whose ETH has no value:
+// geth --dev (the dev L1 backend), anvil and hardhat, and the Kurtosis ethereum-package.
+var devnetChainIDs = []int{1337, 31337, 3151908}
+
+type (
+	// costEstimate is the expected cost of the L1 deployment phase.
+	costEstimate struct {
+		Gas      uint64
+		GasPrice *big.Int
+		// Bond is the initial bond of the dispute game, paid on top of the gas.
+		Bond  *big.Int
+		Total *big.Int
+	}
+
+	// Checker verifies the L1 and the deployer wallet before phase 1 spends any ETH.
+	Checker struct {
+		logger *slog.Logger
+	}
+)
+
+// NewChecker creates a new L1 preflight checker
+func NewChecker() *Checker {
+	return &Checker{
+		logger: logger.Named("l1_preflight"),
+	}
+}
+
+// Check queries the chain ID and the deployer wallet balance on l1-el-url and estimates the cost of the
+// op-deployer apply, the dispute contracts and the dispute game bond. It refuses to proceed when the wallet
+// cannot cover the estimate or the chain is not a known devnet, unless confirmed is set.
+func (c *Checker) Check(ctx context.Context, cfg configs.L2, confirmed bool) error {
+	c.logger.With("l1_el_url", logger.RedactURL(cfg.L1ElURL), "wallet", cfg.Wallet.Address).Info("running L1 preflight")
+
+	client, err := ethclient.DialContext(ctx, cfg.L1ElURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to L1 at %s: %w", logger.RedactURL(cfg.L1ElURL), err)
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query L1 chain ID: %w", err)
+	}
+	if chainID.Int64() != int64(cfg.L1ChainID) {
+		return fmt.Errorf("L1 at %s reports chain ID %s, but l2.l1-chain-id is %d", logger.RedactURL(cfg.L1ElURL), chainID, cfg.L1ChainID)
+	}
+
+	balance, err := client.BalanceAt(ctx, common.HexToAddress(cfg.Wallet.Address), nil)
+	if err != nil {
+		return fmt.Errorf("failed to query balance of %s: %w", cfg.Wallet.Address, err)
+	}
+
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query L1 gas price: %w", err)
+	}
+
+	estimate, err := estimateCost(cfg, gasPrice)
+	if err != nil {
+		return err
+	}
+
+	c.logger.With(
+		"chain_id", chainID,
+		"balance_eth", formatEther(balance),
+		"gas", estimate.Gas,
+		"gas_price_gwei", formatUnits(estimate.GasPrice, params.GWei),
+		"bond_eth", formatEther(estimate.Bond),
+		"estimated_cost_eth", formatEther(estimate.Total),
+	).Info("L1 deployment cost estimated")
+
+	var problems []string
+	if !slices.Contains(devnetChainIDs, cfg.L1ChainID) {
+		problems = append(problems, fmt.Sprintf("L1 chain %d is not a known devnet, the deployment spends real ETH", cfg.L1ChainID))
+	}
+	if balance.Cmp(estimate.Total) < 0 {
+		problems = append(problems, fmt.Sprintf("wallet %s holds %s ETH, the deployment needs about %s ETH", cfg.Wallet.Address, formatEther(balance), formatEther(estimate.Total)))
+	}
+
+	if len(problems) == 0 {
+		c.logger.Info("L1 preflight passed")
+		return nil
+	}
+
+	if confirmed {
+		c.logger.With("problems", problems).Warn("L1 preflight failed. Proceeding because of --yes")
+		return nil
+	}
+
+	return fmt.Errorf("L1 preflight failed: %s. Rerun with --yes to proceed anyway", strings.Join(problems, "; "))
+}
+
+// estimateCost computes the cost of phase 1 at the given gas price. With the calldata deployment target
+// op-deployer only prints the transactions, so only the dispute deployment is paid.
+func estimateCost(cfg configs.L2, gasPrice *big.Int) (costEstimate, error) {
+	bond, ok := new(big.Int).SetString(cfg.Dispute.DisputeGameInitBond, 10)
+	if !ok {
+		return costEstimate{}, fmt.Errorf("invalid l2.dispute.dispute-game-init-bond '%s'", cfg.Dispute.DisputeGameInitBond)
+	}
+
+	gas := uint64(disputeGas)
+	if cfg.DeploymentTarget != "calldata" {
+		gas += opDeployerBaseGas + opDeployerChainGas*uint64(len(cfg.ChainConfigs))
+	}
+
+	total := new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice)
+	total.Mul(total, big.NewInt(100+marginPercent))
+	total.Div(total, big.NewInt(100))
+	total.Add(total, bond)
+
+	return costEstimate{Gas: gas, GasPrice: gasPrice, Bond: bond, Total: total}, nil
+}
+
+// formatEther formats a wei amount as ETH.
+func formatEther(wei *big.Int) string {
+	return formatUnits(wei, params.Ether)
+}
+
+func formatUnits(amount *big.Int, unit float64) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(amount), big.NewFloat(unit)).Text('f', 6)
+}
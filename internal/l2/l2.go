@@ -1,6 +1,7 @@
 package l2
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
@@ -8,8 +9,10 @@ import (
 
 	"github.com/compose-network/local-testnet/configs"
 	"github.com/compose-network/local-testnet/internal/l2/blockscout"
+	"github.com/compose-network/local-testnet/internal/l2/checkpoint"
 	"github.com/compose-network/local-testnet/internal/l2/infra/git"
 	"github.com/compose-network/local-testnet/internal/l2/l1deployment"
+	"github.com/compose-network/local-testnet/internal/l2/l1deployment/preflight"
 	"github.com/compose-network/local-testnet/internal/l2/l2config"
 	"github.com/compose-network/local-testnet/internal/l2/l2runtime"
 	"github.com/compose-network/local-testnet/internal/l2/output"
@@ -29,36 +32,133 @@ var CMD = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		slog.Info("starting l2 command. Validating config", slog.Any("config", configs.Values.L2))
+		opts, err := deployOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		if dryRun, _ := cmd.Flags().GetBool(dryRunFlag); dryRun {
+			planDir, _ := cmd.Flags().GetString(planDirFlag)
+			return DryRun(configs.Values.L2, opts, planDir, cmd.OutOrStdout())
+		}
+
+		return Run(cmd.Context(), configs.Values.L2, opts)
+	},
+}
 
-		if err := configs.Values.L2.Validate(); err != nil {
+// Run validates the L2 configuration and deploys the L2 network from the current working directory.
+func Run(ctx context.Context, cfg configs.L2, opts DeployOptions) error {
+	slog.Info("starting l2 deployment. Validating config", slog.Any("config", cfg))
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	rootDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	if opts.Locked {
+		if opts.Offline {
+			return fmt.Errorf("--locked cannot be combined with --offline: images loaded from a bundle have no registry digest")
+		}
+		if err := applyLock(&cfg, rootDir); err != nil {
 			return err
 		}
+	}
 
-		slog.Info("config validation successful. Starting l2 deployment...")
+	if opts.Offline {
+		if opts.Rebuild {
+			return fmt.Errorf("--rebuild cannot be combined with --offline: builds download dependencies")
+		}
+		if err := checkOfflineImages(ctx, cfg); err != nil {
+			return err
+		}
+	}
 
-		rootDir, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("failed to get working directory: %w", err)
+	// A locked run already pins op-rbuilder, an offline run uses the image it was built to.
+	if !opts.Locked && !opts.Offline {
+		if err := pinOpRbuilder(ctx, &cfg); err != nil {
+			return err
 		}
+	}
+
+	if err := ResolveHostPorts(ctx, &cfg, opts.PortMode, rootDir); err != nil {
+		return err
+	}
+
+	slog.Info("config validation successful. Starting l2 deployment...")
+
+	localnetDir := filepath.Join(rootDir, configs.WorkDir())
+	stateDir := filepath.Join(localnetDir, stateDirName)
+	networksDir := filepath.Join(localnetDir, networksDirName)
+	servicesDir := filepath.Join(localnetDir, servicesDirName)
 
-		localnetDir := filepath.Join(rootDir, localnetDirName)
-		stateDir := filepath.Join(localnetDir, stateDirName)
-		networksDir := filepath.Join(localnetDir, networksDirName)
-		servicesDir := filepath.Join(localnetDir, servicesDirName)
+	l1Orchestrator := l1deployment.NewOrchestrator(rootDir, stateDir, servicesDir)
+	l2ConfigOrchestrator := l2config.NewOrchestrator(rootDir, localnetDir, stateDir, networksDir, servicesDir)
+	runtimeOrchestrator := l2runtime.NewOrchestrator(rootDir, localnetDir, networksDir, servicesDir)
+	cloner := git.NewCloner()
+	if opts.Offline {
+		l2ConfigOrchestrator = l2ConfigOrchestrator.WithOffline()
+		runtimeOrchestrator = runtimeOrchestrator.WithOffline()
+		cloner = cloner.WithOffline()
+	}
+	if opts.Rebuild {
+		l2ConfigOrchestrator = l2ConfigOrchestrator.WithRebuild()
+		runtimeOrchestrator = runtimeOrchestrator.WithRebuild()
+	}
 
-		l1Orchestrator := l1deployment.NewOrchestrator(rootDir, stateDir, servicesDir)
-		l2ConfigOrchestrator := l2config.NewOrchestrator(rootDir, localnetDir, stateDir, networksDir, servicesDir)
-		runtimeOrchestrator := l2runtime.NewOrchestrator(rootDir, localnetDir, networksDir, servicesDir)
+	service := NewService(rootDir, cloner, preflight.NewChecker(), l1Orchestrator, l2ConfigOrchestrator, runtimeOrchestrator, blockscout.New(localnetDir, networksDir, cfg.Lock), output.NewGenerator(), checkpoint.NewStore(stateDir))
 
-		service := NewService(rootDir, git.NewCloner(), l1Orchestrator, l2ConfigOrchestrator, runtimeOrchestrator, blockscout.New(localnetDir, networksDir), output.NewGenerator())
+	if err := service.Deploy(ctx, cfg, opts); err != nil {
+		return fmt.Errorf("l2 deployment failed: %w", err)
+	}
 
-		if err := service.Deploy(cmd.Context(), configs.Values.L2); err != nil {
-			return fmt.Errorf("l2 deployment failed: %w", err)
+	// A locked run used exactly the recorded versions, the lock file stays as it is.
+	// An offline run cannot resolve the digests of the pulled images.
+	if !opts.Locked && !opts.Offline {
+		if err := recordLock(ctx, cfg, rootDir); err != nil {
+			slog.With("err", err.Error()).Warn("failed to update lock file")
 		}
+	}
 
-		slog.Info("l2 deployment completed successfully")
+	slog.Info("l2 deployment completed successfully")
 
-		return nil
-	},
+	return nil
+}
+
+// deployOptionsFromFlags reads the deployment control flags of the l2 command.
+func deployOptionsFromFlags(cmd *cobra.Command) (DeployOptions, error) {
+	resume, err := cmd.Flags().GetBool(resumeFlag)
+	if err != nil {
+		return DeployOptions{}, err
+	}
+
+	yes, err := cmd.Flags().GetBool(yesFlag)
+	if err != nil {
+		return DeployOptions{}, err
+	}
+
+	rebuild, err := cmd.Flags().GetBool(rebuildFlag)
+	if err != nil {
+		return DeployOptions{}, err
+	}
+
+	opts := DeployOptions{Resume: resume, PortMode: configs.Values.Ports.Mode, Yes: yes, Locked: configs.Values.Locked, Offline: configs.Values.Offline, Rebuild: rebuild}
+
+	fromPhase, err := cmd.Flags().GetString(fromPhaseFlag)
+	if err != nil {
+		return DeployOptions{}, err
+	}
+	if fromPhase != "" {
+		phase, err := checkpoint.ParsePhase(fromPhase)
+		if err != nil {
+			return DeployOptions{}, fmt.Errorf("invalid --%s: %w", fromPhaseFlag, err)
+		}
+		opts.FromPhase = phase
+		opts.Resume = true
+	}
+
+	return opts, nil
 }
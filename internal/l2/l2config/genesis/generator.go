@@ -14,6 +14,8 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/pebble"
 
+	"github.com/compose-network/local-testnet/configs"
+	dockerclient "github.com/compose-network/local-testnet/internal/docker"
 	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
 	"github.com/compose-network/local-testnet/internal/l2/infra/filesystem"
 	"github.com/compose-network/local-testnet/internal/l2/path"
@@ -30,19 +32,22 @@ type (
 
 	Generator struct {
 		deployer    deployer
-		docker      *docker.Client
+		docker      *dockerclient.Client
 		writer      filesystem.Writer
 		rootDir     string
 		localnetDir string
 		servicesDir string
 		networksDir string
 		opGethPath  string
+		cfg         configs.L2
+		offline     bool
+		rebuild     bool
 		logger      *slog.Logger
 	}
 )
 
 // NewGenerator creates a new genesis generator
-func NewGenerator(deployer deployer, docker *docker.Client, writer filesystem.Writer, rootDir, localnetDir, servicesDir, networksDir, opGethPath string) *Generator {
+func NewGenerator(deployer deployer, docker *dockerclient.Client, writer filesystem.Writer, rootDir, localnetDir, servicesDir, networksDir, opGethPath string, cfg configs.L2) *Generator {
 	return &Generator{
 		deployer:    deployer,
 		docker:      docker,
@@ -52,10 +57,23 @@ func NewGenerator(deployer deployer, docker *docker.Client, writer filesystem.Wr
 		servicesDir: servicesDir,
 		networksDir: networksDir,
 		opGethPath:  opGethPath,
+		cfg:         cfg,
 		logger:      logger.Named("genesis_generator"),
 	}
 }
 
+// WithOffline makes the generator run geth init with the existing op-geth image instead of building or pulling it.
+func (g *Generator) WithOffline() *Generator {
+	g.offline = true
+	return g
+}
+
+// WithRebuild makes the generator build the op-geth image even when it was built from the checked out source.
+func (g *Generator) WithRebuild() *Generator {
+	g.rebuild = true
+	return g
+}
+
 // Generate generates genesis config for a chain
 func (g *Generator) Generate(ctx context.Context, chainID int, path string, walletAddress, sequencerAddress, genesisBalanceWei, coordinatorPrivateKey string) (string, error) {
 	logger := g.logger.With("chain_id", chainID)
@@ -159,7 +177,7 @@ func (g *Generator) computeGenesisHash(ctx context.Context, chainID int, genesis
 	}
 	defer os.RemoveAll(tmpDataDir)
 
-	const opGethImage = "local/op-geth:dev"
+	opGethImage := docker.OpGethImage(g.cfg)
 
 	if err := g.ensureOpGethImage(ctx, opGethImage); err != nil {
 		return "", fmt.Errorf("failed to ensure op-geth image: %w", err)
@@ -176,7 +194,7 @@ func (g *Generator) computeGenesisHash(ctx context.Context, chainID int, genesis
 	}
 
 	g.logger.With("image", opGethImage).Info("running geth init")
-	_, err = g.docker.Run(ctx, docker.RunOptions{
+	_, err = g.docker.Run(ctx, dockerclient.RunOptions{
 		Image: opGethImage,
 		Cmd: []string{
 			fmt.Sprintf("--networkid=%d", chainID),
@@ -232,21 +250,52 @@ func (g *Generator) computeGenesisHash(ctx context.Context, chainID int, genesis
 	return genesisHash.Hex(), nil
 }
 
-// ensureOpGethImage checks if op-geth image exists, and builds it if not
+// ensureOpGethImage builds the op-geth image unless it exists and was built from the checked out source,
+// or always with --rebuild.
+// A prebuilt image is pulled if missing. Offline the existing image is used as it is.
 func (g *Generator) ensureOpGethImage(ctx context.Context, imageName string) error {
-	exists, err := g.docker.ImageExists(ctx, imageName)
-	if err != nil {
-		return fmt.Errorf("failed to check if image exists: %w", err)
+	if g.offline {
+		exists, err := g.docker.ImageExists(ctx, imageName)
+		if err != nil {
+			return fmt.Errorf("failed to check if image exists: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("op-geth image %s is not available offline", imageName)
+		}
+		g.logger.With("image", imageName).Info("offline, using the existing op-geth image")
+		return nil
 	}
 
-	if exists {
-		g.logger.Info("op-geth image already exists")
-		return nil
+	if g.cfg.PrebuiltImage(configs.RepositoryNameOpGeth) != "" {
+		exists, err := g.docker.ImageExists(ctx, imageName)
+		if err != nil {
+			return fmt.Errorf("failed to check if image exists: %w", err)
+		}
+		if exists {
+			g.logger.With("image", imageName).Info("prebuilt op-geth image already exists")
+			return nil
+		}
+		return g.docker.PullImage(ctx, imageName)
 	}
 
-	g.logger.Info("op-geth image not found, building it using docker compose")
+	revision := docker.NewEnvBuilder(g.rootDir, g.networksDir, g.servicesDir).SourceRevision(ctx, g.cfg, configs.RepositoryNameOpGeth)
+	if g.rebuild {
+		g.logger.With("revision", revision).Info("rebuild requested, building op-geth image using docker compose")
+	} else {
+		upToDate, err := g.docker.ImageBuiltFrom(ctx, imageName, revision)
+		if err != nil {
+			return fmt.Errorf("failed to check if image exists: %w", err)
+		}
+
+		if upToDate {
+			g.logger.With("revision", revision).Info("op-geth image was built from the checked out source. Use --rebuild to force it")
+			return nil
+		}
+
+		g.logger.With("revision", revision).Info("op-geth image not found or built from another source, building it using docker compose")
+	}
 
-	composePath, err := docker.EnsureComposeFile(g.localnetDir)
+	composePath, err := docker.EnsureComposeFile(g.localnetDir, g.cfg)
 	if err != nil {
 		return fmt.Errorf("failed to ensure compose file: %w", err)
 	}
@@ -264,10 +313,13 @@ func (g *Generator) ensureOpGethImage(ctx context.Context, imageName string) err
 		"ROOT_DIR":     rootHostPath,
 		"OP_GETH_PATH": g.opGethPath,
 	}
+	env[docker.SourceRevisionEnv(configs.RepositoryNameOpGeth)] = revision
 
 	g.logger.With("op_geth_path", g.opGethPath, "root_dir", rootHostPath, "compose_file", composePath).Info("building op-geth image")
 
-	if err := docker.ComposeBuild(ctx, composePath, env, "op-geth-a"); err != nil {
+	// All op-geth services share the same image, so building the first one is enough
+	opGethService := docker.OpGethService(g.cfg.ChainNames()[0])
+	if err := docker.ComposeBuild(ctx, composePath, env, opGethService); err != nil {
 		return fmt.Errorf("failed to build op-geth image: %w", err)
 	}
 
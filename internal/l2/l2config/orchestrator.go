@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/compose-network/local-testnet/configs"
+	dockerclient "github.com/compose-network/local-testnet/internal/docker"
 	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
 	"github.com/compose-network/local-testnet/internal/l2/infra/filesystem/json"
 	"github.com/compose-network/local-testnet/internal/l2/l1deployment"
@@ -33,6 +34,8 @@ type Orchestrator struct {
 	stateDir    string
 	networksDir string
 	servicesDir string
+	offline     bool
+	rebuild     bool
 	logger      *slog.Logger
 }
 
@@ -48,32 +51,55 @@ func NewOrchestrator(rootDir, localnetDir, stateDir, networksDir, servicesDir st
 	}
 }
 
+// WithOffline makes the orchestrator generate genesis files with the existing op-geth image instead of
+// building it, since builds download dependencies.
+func (o *Orchestrator) WithOffline() *Orchestrator {
+	o.offline = true
+	return o
+}
+
+// WithRebuild makes the orchestrator build the op-geth image even when it was built from the checked out source.
+func (o *Orchestrator) WithRebuild() *Orchestrator {
+	o.rebuild = true
+	return o
+}
+
 // Execute runs Phase 2: Generate all L2 configuration files
 func (o *Orchestrator) Execute(ctx context.Context, cfg configs.L2, deploymentState l1deployment.DeploymentState) error {
 	o.logger.Info("Phase 2: Starting L2 configuration generation")
 
-	dockerClient, err := docker.New()
+	dockerClient, err := dockerclient.New()
 	if err != nil {
 		return fmt.Errorf("failed to create docker client: %w", err)
 	}
 	defer dockerClient.Close()
 
 	envBuilder := docker.NewEnvBuilder(o.rootDir, o.networksDir, o.servicesDir)
-	opGethPath, err := envBuilder.ResolveRepoPath(cfg.Repositories[configs.RepositoryNameOpGeth], configs.RepositoryNameOpGeth)
-	if err != nil {
-		return fmt.Errorf("failed to resolve op-geth path: %w", err)
+	// A prebuilt op-geth image has no checkout to build from.
+	var opGethPath string
+	if cfg.PrebuiltImage(configs.RepositoryNameOpGeth) == "" {
+		opGethPath, err = envBuilder.ResolveRepoPath(cfg.Repositories[configs.RepositoryNameOpGeth], configs.RepositoryNameOpGeth)
+		if err != nil {
+			return fmt.Errorf("failed to resolve op-geth path: %w", err)
+		}
 	}
 
 	var (
 		writer = json.NewWriter()
 
-		opDeployer   = deployer.NewDeployer(o.rootDir, o.stateDir, cfg.Images[configs.ImageNameOpDeployer].Tag, dockerClient)
-		genesisGen   = genesis.NewGenerator(opDeployer, dockerClient, writer, o.rootDir, o.localnetDir, o.servicesDir, o.networksDir, opGethPath)
+		opDeployer   = deployer.NewDeployer(o.rootDir, o.stateDir, deployer.Image(cfg), dockerClient)
+		genesisGen   = genesis.NewGenerator(opDeployer, dockerClient, writer, o.rootDir, o.localnetDir, o.servicesDir, o.networksDir, opGethPath, cfg)
 		rollupGen    = rollup.NewGenerator(json.NewReader(), opDeployer, writer, o.localnetDir)
 		secretsGen   = secrets.NewGenerator(writer)
 		contractsGen = contracts.NewGenerator(writer)
 		runtimeGen   = runtime.NewGenerator()
 	)
+	if o.offline {
+		genesisGen = genesisGen.WithOffline()
+	}
+	if o.rebuild {
+		genesisGen = genesisGen.WithRebuild()
+	}
 
 	for chainName, chainConfig := range cfg.ChainConfigs {
 		configPath := filepath.Join(o.networksDir, string(chainName))
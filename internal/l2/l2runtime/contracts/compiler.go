@@ -68,8 +68,11 @@ func (c *Compiler) Compile(ctx context.Context, contractNames []string) error {
 func (c *Compiler) installDependencies(ctx context.Context) error {
 	cmd := exec.CommandContext(ctx, "forge", "install")
 	cmd.Dir = c.contractsRootDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	stdout, stderr := logger.CommandOutput("forge")
+	defer stdout.Close()
+	defer stderr.Close()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("forge install failed: %w", err)
@@ -6,10 +6,12 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/compose-network/local-testnet/configs"
+	dockerclient "github.com/compose-network/local-testnet/internal/docker"
 	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
 	"github.com/compose-network/local-testnet/internal/l2/l2config/genesis"
 	"github.com/compose-network/local-testnet/internal/l2/l2config/secrets"
@@ -31,6 +33,8 @@ type Orchestrator struct {
 	localnetDir string
 	networksDir string
 	servicesDir string
+	offline     bool
+	rebuild     bool
 	logger      *slog.Logger
 }
 
@@ -45,6 +49,19 @@ func NewOrchestrator(rootDir, localnetDir, networksDir, servicesDir string) *Orc
 	}
 }
 
+// WithOffline makes the orchestrator run the existing local images instead of building them,
+// since builds download dependencies.
+func (o *Orchestrator) WithOffline() *Orchestrator {
+	o.offline = true
+	return o
+}
+
+// WithRebuild makes the orchestrator build the images even when they were built from the checked out sources.
+func (o *Orchestrator) WithRebuild() *Orchestrator {
+	o.rebuild = true
+	return o
+}
+
 // Execute runs Phase 3: Build images, start services, deploy contracts
 func (o *Orchestrator) Execute(ctx context.Context, cfg configs.L2, gameFactoryAddr common.Address) (map[configs.L2ChainName]map[contracts.ContractName]common.Address, error) {
 	o.logger.Info("Phase 3: Starting L2 runtime operations")
@@ -54,7 +71,7 @@ func (o *Orchestrator) Execute(ctx context.Context, cfg configs.L2, gameFactoryA
 		return nil, fmt.Errorf("failed to setup publisher registry: %w", err)
 	}
 
-	composePath, err := docker.EnsureComposeFile(o.localnetDir)
+	composePath, err := docker.EnsureComposeFile(o.localnetDir, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare docker-compose file: %w", err)
 	}
@@ -64,21 +81,26 @@ func (o *Orchestrator) Execute(ctx context.Context, cfg configs.L2, gameFactoryA
 	if err != nil {
 		return nil, err
 	}
+	envBuilder.AddSourceRevisions(ctx, cfg, envVars)
 
-	o.logger.With("env", envVars).Info("environment variables were constructed. Building compose services")
+	if _, err := docker.WriteEnvFile(o.localnetDir, envVars); err != nil {
+		return nil, fmt.Errorf("failed to write compose env file: %w", err)
+	}
+
+	o.logger.With("env", logger.Env(envVars)).Info("environment variables were constructed. Building compose services")
 	if err := o.buildComposeServices(ctx, composePath, envVars, cfg); err != nil {
 		return nil, fmt.Errorf("failed to build compose services: %w", err)
 	}
 
 	o.logger.Info("docker-compose services built successfully")
-	serviceManager := services.NewManager(o.rootDir, composePath)
+	serviceManager := services.NewManager(o.rootDir, composePath, cfg.ChainNames())
 
 	var flashblocksComposePath string
 	var sidecarComposePath string
 
 	if cfg.Flashblocks.Enabled {
 		o.logger.Info("flashblocks enabled, configuring services to use rollup-boost")
-		flashblocksComposePath, err = docker.EnsureFlashblocksComposeFile(o.localnetDir)
+		flashblocksComposePath, err = docker.EnsureFlashblocksComposeFile(o.localnetDir, cfg)
 		if err != nil {
 			return nil, fmt.Errorf("failed to prepare flashblocks compose file: %w", err)
 		}
@@ -87,9 +109,6 @@ func (o *Orchestrator) Execute(ctx context.Context, cfg configs.L2, gameFactoryA
 		if cfg.Flashblocks.OpRbuilderImageTag != "" {
 			envVars["OP_RBUILDER_IMAGE_TAG"] = cfg.Flashblocks.OpRbuilderImageTag
 		}
-		if cfg.Flashblocks.RollupBoostImageTag != "" {
-			envVars["ROLLUP_BOOST_IMAGE_TAG"] = cfg.Flashblocks.RollupBoostImageTag
-		}
 	}
 
 	if cfg.Sidecar.Enabled {
@@ -97,14 +116,14 @@ func (o *Orchestrator) Execute(ctx context.Context, cfg configs.L2, gameFactoryA
 			return nil, fmt.Errorf("sidecar requires flashblocks to be enabled")
 		}
 		o.logger.Info("sidecar enabled, configuring sidecar services")
-		sidecarComposePath, err = docker.EnsureSidecarComposeFile(o.localnetDir)
+		sidecarComposePath, err = docker.EnsureSidecarComposeFile(o.localnetDir, cfg)
 		if err != nil {
 			return nil, fmt.Errorf("failed to prepare sidecar compose file: %w", err)
 		}
 		serviceManager.WithSidecar(sidecarComposePath)
 	}
 
-	if err := o.waitForNetworkFiles(); err != nil {
+	if err := o.waitForNetworkFiles(cfg.ChainNames()); err != nil {
 		return nil, fmt.Errorf("required network files not ready: %w", err)
 	}
 
@@ -116,9 +135,7 @@ func (o *Orchestrator) Execute(ctx context.Context, cfg configs.L2, gameFactoryA
 	effectiveChainConfigs := cfg.ChainConfigs
 	if cfg.Flashblocks.Enabled {
 		effectiveChainConfigs = o.getFlashblocksChainConfigs(cfg)
-		o.logger.Info("using flashblocks RPC ports for contract deployment",
-			"rollup_a_port", effectiveChainConfigs[configs.L2ChainNameRollupA].RPCPort,
-			"rollup_b_port", effectiveChainConfigs[configs.L2ChainNameRollupB].RPCPort)
+		o.logger.Info("using flashblocks RPC ports for contract deployment", "chain_configs", effectiveChainConfigs)
 	}
 
 	contractDeployer := contracts.NewDeployer(o.networksDir)
@@ -128,13 +145,13 @@ func (o *Orchestrator) Execute(ctx context.Context, cfg configs.L2, gameFactoryA
 	}
 
 	o.logger.Info("restarting op-geth services to apply mailbox configuration")
-	if err := o.restartOpGeth(ctx, composePath, envVars, deployedContracts); err != nil {
+	if err := o.restartOpGeth(ctx, composePath, envVars, cfg.ChainNames(), deployedContracts); err != nil {
 		return nil, fmt.Errorf("failed to restart op-geth services after contract deployment. Error: '%w'", err)
 	}
 
 	if cfg.Sidecar.Enabled {
 		o.logger.Info("restarting sidecar services to apply mailbox configuration")
-		if err := o.restartSidecar(ctx, composePath, flashblocksComposePath, sidecarComposePath, envVars); err != nil {
+		if err := o.restartSidecar(ctx, composePath, flashblocksComposePath, sidecarComposePath, envVars, cfg.ChainNames()); err != nil {
 			return nil, fmt.Errorf("failed to restart sidecar services after contract deployment: %w", err)
 		}
 	}
@@ -144,28 +161,23 @@ func (o *Orchestrator) Execute(ctx context.Context, cfg configs.L2, gameFactoryA
 	return deployedContracts, nil
 }
 
-func (o *Orchestrator) waitForNetworkFiles() error {
+func (o *Orchestrator) waitForNetworkFiles(chains []configs.L2ChainName) error {
 	type fileSpec struct {
 		path  string
 		label string
 	}
-	files := []fileSpec{
-		{
-			path:  filepath.Join(o.networksDir, string(configs.L2ChainNameRollupA), genesis.GenesisFileName),
-			label: "rollup-a genesis",
-		},
-		{
-			path:  filepath.Join(o.networksDir, string(configs.L2ChainNameRollupA), secrets.JWTFileName),
-			label: "rollup-a jwt",
-		},
-		{
-			path:  filepath.Join(o.networksDir, string(configs.L2ChainNameRollupB), genesis.GenesisFileName),
-			label: "rollup-b genesis",
-		},
-		{
-			path:  filepath.Join(o.networksDir, string(configs.L2ChainNameRollupB), secrets.JWTFileName),
-			label: "rollup-b jwt",
-		},
+	files := make([]fileSpec, 0, 2*len(chains))
+	for _, chain := range chains {
+		files = append(files,
+			fileSpec{
+				path:  filepath.Join(o.networksDir, string(chain), genesis.GenesisFileName),
+				label: fmt.Sprintf("%s genesis", chain),
+			},
+			fileSpec{
+				path:  filepath.Join(o.networksDir, string(chain), secrets.JWTFileName),
+				label: fmt.Sprintf("%s jwt", chain),
+			},
+		)
 	}
 
 	deadline := time.Now().Add(120 * time.Second)
@@ -193,22 +205,25 @@ func (o *Orchestrator) waitForNetworkFiles() error {
 	}
 }
 
-func (o *Orchestrator) restartOpGeth(ctx context.Context, composeFilePath string, env map[string]string, deployedContracts map[configs.L2ChainName]map[contracts.ContractName]common.Address) error {
-	mailboxA := deployedContracts[configs.L2ChainNameRollupA][contracts.ContractNameMailbox]
-	mailboxB := deployedContracts[configs.L2ChainNameRollupB][contracts.ContractNameMailbox]
+func (o *Orchestrator) restartOpGeth(ctx context.Context, composeFilePath string, env map[string]string, chains []configs.L2ChainName, deployedContracts map[configs.L2ChainName]map[contracts.ContractName]common.Address) error {
+	mailboxes := make(map[configs.L2ChainName]string, len(chains))
+	for _, chain := range chains {
+		mailbox := deployedContracts[chain][contracts.ContractNameMailbox]
+		if mailbox == (common.Address{}) {
+			return fmt.Errorf("mailbox address not found in deployed contracts for %s", chain)
+		}
 
-	if mailboxA == (common.Address{}) || mailboxB == (common.Address{}) {
-		return fmt.Errorf("mailbox addresses not found in deployed contracts")
+		env["MAILBOX_"+chain.EnvSuffix()] = mailbox.Hex()
+		mailboxes[chain] = mailbox.Hex()
 	}
 
-	env["MAILBOX_A"] = mailboxA.Hex()
-	env["MAILBOX_B"] = mailboxB.Hex()
+	o.logger.Info("restarting op-geth with mailbox addresses", "mailboxes", mailboxes)
 
-	o.logger.Info("restarting op-geth with mailbox addresses",
-		"mailbox_a", mailboxA.Hex(),
-		"mailbox_b", mailboxB.Hex())
+	if _, err := docker.WriteEnvFile(o.localnetDir, env); err != nil {
+		return fmt.Errorf("failed to update compose env file: %w", err)
+	}
 
-	services := []string{"op-geth-a", "op-geth-b"}
+	services := docker.OpGethServices(chains)
 	if err := docker.ComposeRestart(ctx, composeFilePath, env, services...); err != nil {
 		return fmt.Errorf("failed to restart op-geth: %w", err)
 	}
@@ -218,7 +233,7 @@ func (o *Orchestrator) restartOpGeth(ctx context.Context, composeFilePath string
 	return nil
 }
 
-func (o *Orchestrator) restartSidecar(ctx context.Context, composeFilePath, flashblocksComposePath, sidecarComposePath string, env map[string]string) error {
+func (o *Orchestrator) restartSidecar(ctx context.Context, composeFilePath, flashblocksComposePath, sidecarComposePath string, env map[string]string, chains []configs.L2ChainName) error {
 	if sidecarComposePath == "" {
 		return fmt.Errorf("sidecar compose file path is empty")
 	}
@@ -229,7 +244,7 @@ func (o *Orchestrator) restartSidecar(ctx context.Context, composeFilePath, flas
 	}
 	composeFiles = append(composeFiles, sidecarComposePath)
 
-	services := []string{"sidecar-a", "sidecar-b"}
+	services := docker.SidecarServices(chains)
 	if err := docker.ComposeRestartMultiFile(ctx, composeFiles, env, services...); err != nil {
 		return fmt.Errorf("failed to restart sidecar: %w", err)
 	}
@@ -239,28 +254,35 @@ func (o *Orchestrator) restartSidecar(ctx context.Context, composeFilePath, flas
 
 // buildComposeServices builds services using docker-compose
 func (o *Orchestrator) buildComposeServices(ctx context.Context, composeFilePath string, env map[string]string, cfg configs.L2) error {
-	services := []string{
-		"publisher",
-		"op-geth-a",
-		"op-geth-b",
+	if o.offline {
+		o.logger.Info("offline, using the existing images instead of building them")
+		return nil
+	}
+
+	services, err := o.staleServices(ctx, env, cfg)
+	if err != nil {
+		return err
+	}
+	if len(services) == 0 {
+		o.logger.Info("all images were built from the checked out sources, skipping build. Use --rebuild to force it")
+		return nil
 	}
 
 	composeFiles := []string{composeFilePath}
 
 	// Sidecar requires flashblocks, so add flashblocks compose file first
 	if cfg.Sidecar.Enabled {
-		flashblocksComposePath, err := docker.EnsureFlashblocksComposeFile(o.localnetDir)
+		flashblocksComposePath, err := docker.EnsureFlashblocksComposeFile(o.localnetDir, cfg)
 		if err != nil {
 			return fmt.Errorf("failed to prepare flashblocks compose file for build: %w", err)
 		}
 		composeFiles = append(composeFiles, flashblocksComposePath)
 
-		sidecarComposePath, err := docker.EnsureSidecarComposeFile(o.localnetDir)
+		sidecarComposePath, err := docker.EnsureSidecarComposeFile(o.localnetDir, cfg)
 		if err != nil {
 			return fmt.Errorf("failed to prepare sidecar compose file for build: %w", err)
 		}
 		composeFiles = append(composeFiles, sidecarComposePath)
-		services = append(services, "sidecar-a", "sidecar-b")
 	}
 
 	if len(composeFiles) > 1 {
@@ -276,21 +298,52 @@ func (o *Orchestrator) buildComposeServices(ctx context.Context, composeFilePath
 	return nil
 }
 
+// staleServices returns the services whose image has to be built: all of them with --rebuild, otherwise those
+// whose image is missing or was built from another revision of its source than the one in env.
+func (o *Orchestrator) staleServices(ctx context.Context, env map[string]string, cfg configs.L2) ([]string, error) {
+	dockerClient, err := dockerclient.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer dockerClient.Close()
+
+	built := docker.BuiltRepositories(cfg)
+	upToDate := make(map[string]bool)
+	var services []string
+	for _, service := range docker.Services(cfg) {
+		name := configs.RepositoryName(service.BuildFrom)
+		if !slices.Contains(built, name) {
+			continue
+		}
+
+		current, checked := upToDate[service.Image]
+		if !checked {
+			revision := env[docker.SourceRevisionEnv(name)]
+			if !o.rebuild {
+				current, err = dockerClient.ImageBuiltFrom(ctx, service.Image, revision)
+				if err != nil {
+					return nil, fmt.Errorf("failed to inspect image '%s': %w", service.Image, err)
+				}
+			}
+			upToDate[service.Image] = current
+			o.logger.With("image", service.Image, "revision", revision, "up_to_date", current).Info("checked source revision of image")
+		}
+		if !current {
+			services = append(services, service.Name)
+		}
+	}
+
+	return services, nil
+}
+
 // getFlashblocksChainConfigs returns chain configs with op-rbuilder RPC ports.
 func (o *Orchestrator) getFlashblocksChainConfigs(cfg configs.L2) map[configs.L2ChainName]configs.Chain {
 	result := make(map[configs.L2ChainName]configs.Chain)
 
 	for chainName, chainCfg := range cfg.ChainConfigs {
 		modifiedCfg := chainCfg
-		switch chainName {
-		case configs.L2ChainNameRollupA:
-			if cfg.Flashblocks.RollupARPCPort > 0 {
-				modifiedCfg.RPCPort = cfg.Flashblocks.RollupARPCPort
-			}
-		case configs.L2ChainNameRollupB:
-			if cfg.Flashblocks.RollupBRPCPort > 0 {
-				modifiedCfg.RPCPort = cfg.Flashblocks.RollupBRPCPort
-			}
+		if chainCfg.FlashblocksRPCPort > 0 {
+			modifiedCfg.RPCPort = chainCfg.FlashblocksRPCPort
 		}
 		result[chainName] = modifiedCfg
 	}
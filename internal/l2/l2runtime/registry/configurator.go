@@ -11,6 +11,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
 	"github.com/compose-network/local-testnet/internal/logger"
 )
 
@@ -115,13 +116,6 @@ func (c *Configurator) generateRollupToml(registryNetworkDir, chainName string,
 	}
 	defer file.Close()
 
-	// Extract suffix from chain name (e.g., "rollup-a" -> "a")
-	// The sequencer host is "op-geth-a" not "op-geth-rollup-a"
-	suffix := chainName
-	if len(chainName) > 7 && chainName[:7] == "rollup-" {
-		suffix = chainName[7:]
-	}
-
 	data := struct {
 		ChainName      string
 		ChainID        uint64
@@ -133,7 +127,7 @@ func (c *Configurator) generateRollupToml(registryNetworkDir, chainName string,
 		ChainName:      chainName,
 		ChainID:        uint64(chainCfg.ID),
 		RPCPort:        chainCfg.RPCPort,
-		SequencerHost:  "op-geth-" + suffix,
+		SequencerHost:  docker.OpGethService(configs.L2ChainName(chainName)),
 		MailboxAddress: "0x0000000000000000000000000000000000000000", // Placeholder: contracts not deployed yet
 		L2GenesisTime:  0,                                            // Use 0 for testnet genesis time
 	}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/compose-network/local-testnet/configs"
 	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
 	"github.com/compose-network/local-testnet/internal/logger"
 )
@@ -13,6 +14,7 @@ import (
 type Manager struct {
 	rootDir                    string
 	composeFilePath            string
+	chains                     []configs.L2ChainName
 	flashblocksComposeFilePath string
 	sidecarComposeFilePath     string
 	flashblocksEnabled         bool
@@ -21,10 +23,11 @@ type Manager struct {
 }
 
 // NewManager creates a new service manager
-func NewManager(rootDir, composeFilePath string) *Manager {
+func NewManager(rootDir, composeFilePath string, chains []configs.L2ChainName) *Manager {
 	return &Manager{
 		rootDir:         rootDir,
 		composeFilePath: composeFilePath,
+		chains:          chains,
 		logger:          logger.Named("service_manager"),
 	}
 }
@@ -45,36 +48,26 @@ func (m *Manager) WithSidecar(sidecarComposeFilePath string) *Manager {
 
 // StartAll starts all L2 services
 func (m *Manager) StartAll(ctx context.Context, env map[string]string) error {
-	services := []string{
-		"publisher",
-		"op-geth-a",
-		"op-geth-b",
-		"op-node-a",
-		"op-node-b",
-		"op-batcher-a",
-		"op-batcher-b",
-		"op-proposer-a",
-		"op-proposer-b",
+	services := []string{docker.PublisherService}
+	for _, chain := range m.chains {
+		services = append(services,
+			docker.OpGethService(chain),
+			docker.OpNodeService(chain),
+			docker.OpBatcherService(chain),
+			docker.OpProposerService(chain),
+		)
 	}
 
 	composeFiles := []string{m.composeFilePath}
 
 	if m.flashblocksEnabled && m.flashblocksComposeFilePath != "" {
 		composeFiles = append(composeFiles, m.flashblocksComposeFilePath)
-		services = append(services,
-			"op-rbuilder-a",
-			"op-rbuilder-b",
-			"rollup-boost-a",
-			"rollup-boost-b",
-		)
+		services = append(services, m.flashblocksServices()...)
 	}
 
 	if m.sidecarEnabled && m.sidecarComposeFilePath != "" {
 		composeFiles = append(composeFiles, m.sidecarComposeFilePath)
-		services = append(services,
-			"sidecar-a",
-			"sidecar-b",
-		)
+		services = append(services, docker.SidecarServices(m.chains)...)
 	}
 
 	if len(composeFiles) > 1 {
@@ -101,12 +94,7 @@ func (m *Manager) StartFlashblocks(ctx context.Context, env map[string]string) e
 		return fmt.Errorf("flashblocks not enabled or compose file not set")
 	}
 
-	services := []string{
-		"op-rbuilder-a",
-		"op-rbuilder-b",
-		"rollup-boost-a",
-		"rollup-boost-b",
-	}
+	services := m.flashblocksServices()
 
 	m.logger.With("services", services).Info("starting flashblocks services")
 
@@ -117,3 +105,12 @@ func (m *Manager) StartFlashblocks(ctx context.Context, env map[string]string) e
 	m.logger.Info("flashblocks services started successfully")
 	return nil
 }
+
+// flashblocksServices returns op-rbuilder and rollup-boost service names for every chain
+func (m *Manager) flashblocksServices() []string {
+	services := make([]string, 0, 2*len(m.chains))
+	for _, chain := range m.chains {
+		services = append(services, docker.OpRbuilderService(chain), docker.RollupBoostService(chain))
+	}
+	return services
+}
@@ -0,0 +1,173 @@
+package l2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/compose-network/local-testnet/configs"
+	dockerclient "github.com/compose-network/local-testnet/internal/docker"
+	"github.com/compose-network/local-testnet/internal/l2/blockscout"
+	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
+	"github.com/compose-network/local-testnet/internal/l2/infra/git"
+	"github.com/compose-network/local-testnet/internal/l2/l1deployment/deployer"
+	"github.com/compose-network/local-testnet/internal/lock"
+)
+
+// LockPath returns the path of the lock file of the selected instance, next to the config file in rootDir.
+func LockPath(rootDir string) string {
+	return filepath.Join(rootDir, configs.ScopedName(lock.FileName))
+}
+
+// applyLock pins cfg to the lock file: cloned repositories are checked out at the recorded commit and pulled
+// images are run by digest. Every repository and image the deployment uses must be recorded, with the
+// configured URL and ref, so a locked run never silently resolves a version on its own.
+func applyLock(cfg *configs.L2, rootDir string) error {
+	path := LockPath(rootDir)
+	pins, found, err := lock.Load(path)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("--locked requires %s. Run once without --locked to write it", path)
+	}
+
+	var errs []error
+	repositories := cfg.SourceRepositories()
+	for name, repo := range repositories {
+		if repo.URL != "" {
+			pinned, err := lockedRepository(pins, string(name), repo.URL, repo.Branch)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			repo.Branch = pinned.Commit
+		}
+		repositories[name] = repo
+	}
+	if cfg.Flashblocks.Enabled {
+		if _, err := lockedRepository(pins, docker.OpRbuilderName, docker.OpRbuilderRepository, docker.OpRbuilderRef); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, image := range pulledImages(*cfg) {
+		if _, ok := pins.Images[image]; !ok {
+			errs = append(errs, fmt.Errorf("image %s is not recorded", image))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s does not match the configuration. Run without --locked to update it: %w", path, errors.Join(errs...))
+	}
+
+	// Repositories of prebuilt components are kept, they are not cloned.
+	pinnedRepositories := maps.Clone(cfg.Repositories)
+	maps.Copy(pinnedRepositories, repositories)
+	cfg.Repositories = pinnedRepositories
+	cfg.Lock = pins
+	slog.With("path", path).Info("repositories and images pinned by lock file")
+
+	return nil
+}
+
+// lockedRepository returns the lock entry of a repository, which must have been resolved from url and ref.
+func lockedRepository(pins lock.File, name, url, ref string) (lock.Repository, error) {
+	pinned, ok := pins.Repositories[name]
+	switch {
+	case !ok:
+		return lock.Repository{}, fmt.Errorf("repository %s is not recorded", name)
+	case pinned.URL != url || pinned.Ref != ref:
+		return lock.Repository{}, fmt.Errorf("repository %s is recorded for %s at '%s', configured is %s at '%s'", name, pinned.URL, pinned.Ref, url, ref)
+	}
+	return pinned, nil
+}
+
+// pinOpRbuilder resolves the op-rbuilder ref to a commit before deployment, so the image is built from the
+// same commit the lock file records.
+func pinOpRbuilder(ctx context.Context, cfg *configs.L2) error {
+	if !cfg.Flashblocks.Enabled {
+		return nil
+	}
+
+	commit, err := git.NewCloner().RemoteCommit(ctx, docker.OpRbuilderRepository, docker.OpRbuilderRef)
+	if err != nil {
+		return fmt.Errorf("failed to resolve op-rbuilder commit: %w", err)
+	}
+
+	repositories := maps.Clone(cfg.Lock.Repositories)
+	if repositories == nil {
+		repositories = make(map[string]lock.Repository, 1)
+	}
+	repositories[docker.OpRbuilderName] = lock.Repository{URL: docker.OpRbuilderRepository, Ref: docker.OpRbuilderRef, Commit: commit}
+	cfg.Lock.Repositories = repositories
+
+	return nil
+}
+
+// recordLock writes the commits of the cloned repositories and op-rbuilder, and the digests of the pulled
+// images, to the lock file. op-rbuilder is recorded at the commit pinOpRbuilder resolved.
+func recordLock(ctx context.Context, cfg configs.L2, rootDir string) error {
+	cloner := git.NewCloner()
+	servicesDir := filepath.Join(rootDir, configs.WorkDir(), servicesDirName)
+
+	repositories := cfg.SourceRepositories()
+	pins := lock.File{
+		Repositories: make(map[string]lock.Repository, len(repositories)+1),
+		Images:       make(map[string]string),
+	}
+	for _, name := range slices.Sorted(maps.Keys(repositories)) {
+		repo := repositories[name]
+		if repo.URL == "" {
+			continue
+		}
+		commit, err := cloner.Commit(ctx, filepath.Join(servicesDir, string(name)))
+		if err != nil {
+			return err
+		}
+		pins.Repositories[string(name)] = lock.Repository{URL: repo.URL, Ref: repo.Branch, Commit: commit}
+	}
+	if pinned, ok := cfg.Lock.Repositories[docker.OpRbuilderName]; ok {
+		pins.Repositories[docker.OpRbuilderName] = pinned
+	}
+
+	client, err := dockerclient.New()
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer client.Close()
+
+	for _, image := range pulledImages(cfg) {
+		digest, err := client.ImageDigest(ctx, image)
+		if err != nil {
+			return err
+		}
+		pins.Images[image] = digest
+	}
+
+	path := LockPath(rootDir)
+	if err := lock.Record(path, pins); err != nil {
+		return err
+	}
+	slog.With("path", path).Info("resolved repositories and images recorded in lock file")
+
+	return nil
+}
+
+// pulledImages lists the registry images the deployment uses: op-deployer, the pulled L2 services
+// and Blockscout. Images built from a repository are pinned through the repository's commit.
+func pulledImages(cfg configs.L2) []string {
+	images := []string{deployer.Image(cfg)}
+	for _, service := range docker.Services(cfg) {
+		if service.BuildFrom == "" {
+			images = append(images, service.Image)
+		}
+	}
+	if cfg.Blockscout.Enabled {
+		images = append(images, blockscout.Images(cfg.Lock)...)
+	}
+
+	slices.Sort(images)
+	return slices.Compact(images)
+}
@@ -15,6 +15,7 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// fileName is written to the directory commands run from, prefixed with the instance name, see configs.ScopedName.
 const fileName = "output.yaml"
 
 type Generator struct {
@@ -24,28 +25,31 @@ func NewGenerator() *Generator {
 	return &Generator{}
 }
 
-func (g *Generator) Generate(_ context.Context, deployedContracts map[configs.L2ChainName]map[contracts.ContractName]common.Address) error {
+func (g *Generator) Generate(_ context.Context, cfg configs.L2, deployedContracts map[configs.L2ChainName]map[contracts.ContractName]common.Address) error {
 	compiledContracts, err := contracts.LoadCompiledContracts()
 	if err != nil {
 		return fmt.Errorf("could not load compiled contracts. Err: '%w'", err)
 	}
 
-	//NOTE: contracts on both rollups have the same address, so we can just take from one of them
-	chainContracts := deployedContracts[configs.L2ChainNameRollupA]
+	chainNames := cfg.ChainNames()
+	chainConfigs := make(map[configs.L2ChainName]ChainConfig, len(chainNames))
+	for _, chainName := range chainNames {
+		chainConfigs[chainName] = ChainConfig{
+			ID:     cfg.ChainConfigs[chainName].ID,
+			RPCURL: buildURL("http", "localhost", cfg.ChainConfigs[chainName].RPCPort),
+			PK:     cfg.Wallet.PrivateKey,
+		}
+	}
+
+	//NOTE: contracts on all rollups have the same address, so we can just take from one of them
+	var chainContracts map[contracts.ContractName]common.Address
+	if len(chainNames) > 0 {
+		chainContracts = deployedContracts[chainNames[0]]
+	}
 	model := &Model{
 		L2: L2{
-			ChainConfigs: map[configs.L2ChainName]ChainConfig{
-				configs.L2ChainNameRollupA: {
-					ID:     configs.Values.L2.ChainConfigs[configs.L2ChainNameRollupA].ID,
-					RPCURL: buildURL("http", "localhost", configs.Values.L2.ChainConfigs[configs.L2ChainNameRollupA].RPCPort),
-					PK:     configs.Values.L2.Wallet.PrivateKey,
-				},
-				configs.L2ChainNameRollupB: {
-					ID:     configs.Values.L2.ChainConfigs[configs.L2ChainNameRollupB].ID,
-					RPCURL: buildURL("http", "localhost", configs.Values.L2.ChainConfigs[configs.L2ChainNameRollupB].RPCPort),
-					PK:     configs.Values.L2.Wallet.PrivateKey,
-				},
-			},
+			ChainConfigs: chainConfigs,
+			HostPorts:    cfg.HostPorts,
 			Contracts: map[string]ContractConfig{
 				strings.ToLower(contracts.ContractNameBridge): {
 					Address: chainContracts[contracts.ContractNameBridge],
@@ -68,7 +72,7 @@ func (g *Generator) Generate(_ context.Context, deployedContracts map[configs.L2
 		return fmt.Errorf("could not marshal output model. Err: '%w'", err)
 	}
 
-	if err := os.WriteFile(fileName, data, 0644); err != nil {
+	if err := os.WriteFile(configs.ScopedName(fileName), data, 0644); err != nil {
 		return fmt.Errorf("could not write output file. Err: '%w'", err)
 	}
 
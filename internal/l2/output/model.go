@@ -12,6 +12,7 @@ type (
 	}
 	L2 struct {
 		ChainConfigs map[configs.L2ChainName]ChainConfig `yaml:"chain-configs"`
+		HostPorts    map[string]int                      `yaml:"host-ports,omitempty"`
 		Contracts    map[string]ContractConfig           `yaml:"contracts"`
 	}
 	ChainConfig struct {
@@ -1,11 +1,8 @@
 package l2
 
 // Path constants for L2 deployment artifacts and runtime directories.
-// All paths are relative to the project root directory.
+// All paths are relative to the work directory of the selected instance, see configs.WorkDir.
 const (
-	// localnetDirName is the root directory for all L2 runtime artifacts
-	localnetDirName = ".localnet"
-
 	// servicesDirName is the subdirectory for cloned repositories (op-geth, publisher, etc.)
 	servicesDirName = "services"
 
@@ -17,4 +14,7 @@ const (
 
 	// compiledContractsDirName is the subdirectory for compiled contract artifacts
 	compiledContractsDirName = "compiled-contracts"
+
+	// planDirName is the default subdirectory --dry-run writes the planned artifacts to
+	planDirName = "plan"
 )
@@ -0,0 +1,352 @@
+package l2
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/l2/blockscout"
+	"github.com/compose-network/local-testnet/internal/l2/checkpoint"
+	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
+	"github.com/compose-network/local-testnet/internal/l2/infra/filesystem/json"
+	"github.com/compose-network/local-testnet/internal/l2/l1deployment"
+	"github.com/compose-network/local-testnet/internal/l2/l1deployment/deployer"
+	"github.com/compose-network/local-testnet/internal/l2/l1deployment/dispute"
+	"github.com/compose-network/local-testnet/internal/l2/l2config/crypto"
+	"github.com/compose-network/local-testnet/internal/l2/l2runtime/registry"
+	"github.com/compose-network/local-testnet/internal/logger"
+	"github.com/compose-network/local-testnet/internal/ports"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// planFileName is the summary written next to the planned artifacts.
+	planFileName = "plan.txt"
+	// planDisputeDirName holds the files the dispute deployment writes into the compose-contracts clone.
+	planDisputeDirName = "dispute"
+)
+
+// DryRun validates the configuration, writes every artifact the deployment generates before it needs
+// the L1 (intent.toml, networks.toml, registry, compose files) to planDir and prints a summary of the
+// repositories, images, services and ports the deployment would use. Neither Docker nor the L1 is contacted.
+// An empty planDir defaults to <work dir>/plan.
+func DryRun(cfg configs.L2, opts DeployOptions, planDir string, out io.Writer) error {
+	slog.Info("planning l2 deployment. Validating config", slog.Any("config", cfg))
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	rootDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	localnetDir := filepath.Join(rootDir, configs.WorkDir())
+
+	if planDir == "" {
+		planDir = filepath.Join(localnetDir, planDirName)
+	}
+	planDir, err = filepath.Abs(planDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve plan directory: %w", err)
+	}
+
+	if planDir == localnetDir || planDir == rootDir {
+		return fmt.Errorf("--%s must not be the work directory or the current directory, a plan replaces generated files in it", planDirFlag)
+	}
+
+	if opts.Locked {
+		if err := applyLock(&cfg, rootDir); err != nil {
+			return err
+		}
+	}
+
+	// Free ports are only known once Docker is asked, so the plan keeps the ports of the last deployment.
+	if err := applyRecordedHostPorts(&cfg, rootDir); err != nil {
+		return err
+	}
+
+	if err := writePlanArtifacts(cfg, rootDir, localnetDir, planDir); err != nil {
+		return err
+	}
+
+	summary, err := planSummary(cfg, opts, localnetDir, planDir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(planDir, planFileName), summary, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", planFileName, err)
+	}
+	if _, err := out.Write(summary); err != nil {
+		return err
+	}
+
+	slog.With("plan_dir", planDir).Info("l2 deployment plan written")
+
+	return nil
+}
+
+// writePlanArtifacts renders the generated files of phases 1 and 3 into planDir. Addresses that only exist
+// after the L1 deployment, like the DisputeGameFactory, are left as zero addresses.
+func writePlanArtifacts(cfg configs.L2, rootDir, localnetDir, planDir string) error {
+	// Artifacts of a previous plan may not be generated with the current configuration.
+	for _, name := range append(generatedArtifacts, planDisputeDirName, planFileName) {
+		if err := os.RemoveAll(filepath.Join(planDir, name)); err != nil {
+			return fmt.Errorf("failed to remove previous plan artifact %s: %w", name, err)
+		}
+	}
+	if err := os.MkdirAll(planDir, 0755); err != nil {
+		return fmt.Errorf("failed to create plan directory: %w", err)
+	}
+
+	coordinatorAddress, err := crypto.AddressFromPrivateKey(cfg.CoordinatorPrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to derive coordinator address: %w", err)
+	}
+	intentWriter := deployer.NewIntentWriter(filepath.Join(planDir, stateDirName), json.NewWriter())
+	if err := intentWriter.WriteIntent(cfg.Wallet.Address, coordinatorAddress, cfg.L1ChainID, cfg.ChainConfigs); err != nil {
+		return fmt.Errorf("failed to write intent: %w", err)
+	}
+
+	disputeDir := filepath.Join(planDir, planDisputeDirName)
+	if err := os.MkdirAll(disputeDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", disputeDir, err)
+	}
+	if err := dispute.WriteNetworksToml(filepath.Join(disputeDir, "networks.toml"), cfg); err != nil {
+		return fmt.Errorf("failed to write networks.toml: %w", err)
+	}
+
+	if err := registry.NewConfigurator().SetupRegistry(planDir, cfg, common.Address{}); err != nil {
+		return fmt.Errorf("failed to write publisher registry: %w", err)
+	}
+
+	if _, err := docker.EnsureComposeFile(planDir, cfg); err != nil {
+		return fmt.Errorf("failed to write docker-compose file: %w", err)
+	}
+	if cfg.Flashblocks.Enabled || cfg.Sidecar.Enabled {
+		if _, err := docker.EnsureFlashblocksComposeFile(planDir, cfg); err != nil {
+			return fmt.Errorf("failed to write flashblocks compose file: %w", err)
+		}
+	}
+	if cfg.Sidecar.Enabled {
+		if _, err := docker.EnsureSidecarComposeFile(planDir, cfg); err != nil {
+			return fmt.Errorf("failed to write sidecar compose file: %w", err)
+		}
+	}
+
+	// The environment points at the real work directory, where the deployment reads and writes.
+	envBuilder := docker.NewEnvBuilder(rootDir, filepath.Join(localnetDir, networksDirName), filepath.Join(localnetDir, servicesDirName))
+	envVars, err := envBuilder.BuildComposeEnv(cfg, common.Address{})
+	if err != nil {
+		return err
+	}
+	if _, err := docker.WriteEnvFile(planDir, envVars); err != nil {
+		return fmt.Errorf("failed to write compose env file: %w", err)
+	}
+
+	if cfg.Blockscout.Enabled {
+		state := l1deployment.DeploymentState{SystemConfigProxyAddresses: make(map[configs.L2ChainName]common.Address)}
+		for _, name := range cfg.ChainNames() {
+			state.SystemConfigProxyAddresses[name] = common.Address{}
+		}
+		rollupConfigs, err := generateBlockscoutConfig(cfg, state)
+		if err != nil {
+			return fmt.Errorf("failed to generate Blockscout chain configs: %w", err)
+		}
+		if _, err := blockscout.New(planDir, filepath.Join(planDir, networksDirName), cfg.Lock).Render(rollupConfigs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// planSummary describes what a deployment with cfg and opts would do.
+func planSummary(cfg configs.L2, opts DeployOptions, localnetDir, planDir string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	instance := cmp.Or(configs.Values.Instance, "default")
+	fmt.Fprintf(w, "L2 deployment plan\n\n")
+	fmt.Fprintf(w, "instance:\t%s\n", instance)
+	fmt.Fprintf(w, "work dir:\t%s\n", localnetDir)
+	fmt.Fprintf(w, "L1:\tchain %d at %s (beacon %s)\n", cfg.L1ChainID, logger.RedactURL(cfg.L1ElURL), logger.RedactURL(cfg.L1ClURL))
+	fmt.Fprintf(w, "deployer wallet:\t%s\n", cfg.Wallet.Address)
+	fmt.Fprintf(w, "deployment target:\t%s\n", cfg.DeploymentTarget)
+	fmt.Fprintf(w, "compose network:\t%s\n", cfg.ComposeNetworkName)
+	if opts.Locked {
+		fmt.Fprintf(w, "versions:\tpinned by %s\n", LockPath(filepath.Dir(localnetDir)))
+	} else {
+		fmt.Fprintf(w, "versions:\tresolved on deployment and recorded in %s\n", LockPath(filepath.Dir(localnetDir)))
+	}
+	for _, name := range cfg.ChainNames() {
+		fmt.Fprintf(w, "rollup %s:\tchain %d, RPC on host port %d\n", name, cfg.ChainConfigs[name].ID, cfg.ChainConfigs[name].RPCPort)
+	}
+
+	phases, err := planPhases(cfg, opts, localnetDir)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(w, "\nPHASE\tACTION\n")
+	for _, phase := range phases {
+		fmt.Fprintf(w, "%s\t%s\n", phase[0], phase[1])
+	}
+
+	fmt.Fprintf(w, "\nREPOSITORY\tSOURCE\tREF\tACTION\n")
+	repositories := cfg.SourceRepositories()
+	for _, name := range slices.Sorted(maps.Keys(repositories)) {
+		source, ref, action := planRepository(repositories[name], filepath.Join(localnetDir, servicesDirName, string(name)))
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, source, ref, action)
+	}
+
+	fmt.Fprintf(w, "\nIMAGE\tACTION\n")
+	for _, image := range planImages(cfg) {
+		fmt.Fprintf(w, "%s\t%s\n", image[0], image[1])
+	}
+
+	fmt.Fprintf(w, "\nSERVICE\tIMAGE\tHOST PORTS\n")
+	for _, service := range docker.Services(cfg) {
+		bindings := make([]string, 0, len(service.Ports))
+		for _, binding := range service.Ports {
+			bindings = append(bindings, fmt.Sprintf("%d->%d", binding.Host, binding.Container))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", service.Name, service.Image, strings.Join(bindings, ", "))
+	}
+	if cfg.Blockscout.Enabled {
+		for _, name := range cfg.ChainNames() {
+			fmt.Fprintf(w, "blockscout-%s\tdb, redis, backend, frontend, proxy\t%d->80\n", name.Suffix(), blockscout.PublicPort(cfg, name))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.HostPorts) == 0 {
+		fmt.Fprintf(&buf, "\nHost ports are the configured defaults.")
+	} else {
+		fmt.Fprintf(&buf, "\nHost ports are the ones recorded by the last deployment.")
+	}
+	if opts.PortMode == ports.ModeAuto {
+		fmt.Fprintf(&buf, " Ports taken on deployment are reassigned (auto port mode).\n")
+	} else {
+		fmt.Fprintf(&buf, " Ports taken on deployment fail it (fixed port mode).\n")
+	}
+
+	artifacts, err := planArtifacts(planDir)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&buf, "\nArtifacts in %s:\n", planDir)
+	for _, artifact := range artifacts {
+		fmt.Fprintf(&buf, "  %s\n", artifact)
+	}
+	fmt.Fprintf(&buf, "\nAddresses deployed in phase 1 are zero in these files. The op-deployer state, genesis.json,\n")
+	fmt.Fprintf(&buf, "rollup.json and secrets of every rollup are only generated after the L1 deployment.\n")
+	fmt.Fprintf(&buf, "The .env file contains private keys.\n")
+
+	return buf.Bytes(), nil
+}
+
+// planPhases returns every deployment phase with whether it would run, honoring the checkpoint with --resume.
+func planPhases(cfg configs.L2, opts DeployOptions, localnetDir string) ([][2]string, error) {
+	service := &Service{
+		checkpointStore: checkpoint.NewStore(filepath.Join(localnetDir, stateDirName)),
+		logger:          logger.Named("l2_plan"),
+	}
+	cp, err := service.loadCheckpoint(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	phases := make([][2]string, 0, len(checkpoint.Phases))
+	for _, phase := range checkpoint.Phases {
+		action := "run"
+		switch {
+		case cp.IsCompleted(phase):
+			action = fmt.Sprintf("skip (completed at %s)", cp.Completed[phase].Format("2006-01-02 15:04:05"))
+		case phase == checkpoint.PhaseBlockscout && !cfg.Blockscout.Enabled:
+			action = "skip (blockscout disabled)"
+		case phase == checkpoint.PhaseL1:
+			action = "run (spends L1 gas from the deployer wallet)"
+		}
+		phases = append(phases, [2]string{string(phase), action})
+	}
+	return phases, nil
+}
+
+// planRepository describes where a repository comes from and what the clone phase would do with it.
+func planRepository(repo configs.Repository, clonePath string) (source, ref, action string) {
+	if repo.URL == "" {
+		absPath, err := filepath.Abs(repo.LocalPath)
+		if err != nil {
+			absPath = repo.LocalPath
+		}
+		return absPath, "-", "use local checkout"
+	}
+
+	if _, err := os.Stat(filepath.Join(clonePath, ".git")); err == nil {
+		return repo.URL, repo.Branch, "fetch and reset existing clone in " + clonePath
+	}
+	return repo.URL, repo.Branch, "clone into " + clonePath
+}
+
+// planImages lists every image the deployment uses, and whether it is pulled or built.
+func planImages(cfg configs.L2) [][2]string {
+	var images [][2]string
+	add := func(image, action string) {
+		if !slices.ContainsFunc(images, func(entry [2]string) bool { return entry[0] == image }) {
+			images = append(images, [2]string{image, action})
+		}
+	}
+
+	add(deployer.Image(cfg), "pull if missing")
+	for _, service := range docker.Services(cfg) {
+		if service.BuildFrom != "" {
+			add(service.Image, "build from "+service.BuildFrom)
+			continue
+		}
+		add(service.Image, "pull if missing")
+	}
+	if cfg.Blockscout.Enabled {
+		for _, image := range blockscout.Images(cfg.Lock) {
+			add(image, "pull if missing")
+		}
+	}
+	return images
+}
+
+// planArtifacts lists the files written to planDir, relative to it.
+func planArtifacts(planDir string) ([]string, error) {
+	var artifacts []string
+	err := filepath.WalkDir(planDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(planDir, path)
+		if err != nil {
+			return err
+		}
+		if rel != planFileName {
+			artifacts = append(artifacts, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plan artifacts: %w", err)
+	}
+	return artifacts, nil
+}
@@ -0,0 +1,80 @@
+package l2
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/compose-network/local-testnet/configs"
+	dockerclient "github.com/compose-network/local-testnet/internal/docker"
+	"github.com/compose-network/local-testnet/internal/l2/blockscout"
+	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
+	"github.com/compose-network/local-testnet/internal/ports"
+)
+
+// ResolveHostPorts checks (fixed mode) or assigns (auto mode) the host ports of every enabled L2 service,
+// records them in the port map file under rootDir and applies them to cfg.
+// Ports published by running L2 containers belong to a previous deployment and count as free.
+func ResolveHostPorts(ctx context.Context, cfg *configs.L2, mode ports.Mode, rootDir string) error {
+	requested := requestedHostPorts(*cfg)
+
+	path := filepath.Join(rootDir, configs.WorkDir(), ports.FileName)
+	previous, err := ports.Load(path)
+	if err != nil {
+		return err
+	}
+
+	owned, err := publishedPorts(ctx, dockerclient.L2Stack())
+	if err != nil {
+		return err
+	}
+
+	resolved, err := ports.Resolve(requested, mode, previous, owned)
+	if err != nil {
+		return err
+	}
+
+	for _, port := range requested {
+		if resolved[port.Name] != port.Default {
+			slog.With("port", port.Name, "default", port.Default, "host_port", resolved[port.Name]).Info("host port reassigned")
+		}
+	}
+
+	if err := ports.Record(path, resolved); err != nil {
+		return err
+	}
+	docker.ApplyHostPorts(cfg, resolved)
+
+	return nil
+}
+
+// requestedHostPorts lists the host ports of every enabled L2 service with their configured or derived defaults.
+func requestedHostPorts(cfg configs.L2) []ports.Port {
+	requested := docker.HostPorts(cfg)
+	if cfg.Blockscout.Enabled {
+		requested = append(requested, blockscout.HostPorts(cfg)...)
+	}
+	return requested
+}
+
+// applyRecordedHostPorts applies the port map of the last deployment, so re-rendered compose files
+// keep publishing the same ports.
+func applyRecordedHostPorts(cfg *configs.L2, rootDir string) error {
+	recorded, err := ports.Load(filepath.Join(rootDir, configs.WorkDir(), ports.FileName))
+	if err != nil {
+		return err
+	}
+	docker.ApplyHostPorts(cfg, recorded)
+	return nil
+}
+
+func publishedPorts(ctx context.Context, stack string) ([]int, error) {
+	client, err := dockerclient.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer client.Close()
+
+	return client.PublishedPorts(ctx, stack)
+}
@@ -9,10 +9,13 @@ import (
 
 	"github.com/compose-network/local-testnet/configs"
 	"github.com/compose-network/local-testnet/internal/l2/blockscout"
+	"github.com/compose-network/local-testnet/internal/l2/checkpoint"
+	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
 	"github.com/compose-network/local-testnet/internal/l2/infra/git"
 	"github.com/compose-network/local-testnet/internal/l2/l1deployment"
 	"github.com/compose-network/local-testnet/internal/l2/l2runtime/contracts"
 	"github.com/compose-network/local-testnet/internal/logger"
+	"github.com/compose-network/local-testnet/internal/ports"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -21,6 +24,9 @@ type (
 	cloner interface {
 		CloneAll(ctx context.Context, baseDir string, repos []git.Repository) error
 	}
+	l1Preflight interface {
+		Check(ctx context.Context, cfg configs.L2, confirmed bool) error
+	}
 	l1Orchestrator interface {
 		Execute(ctx context.Context, cfg configs.L2) (l1deployment.DeploymentState, error)
 	}
@@ -34,17 +40,19 @@ type (
 		Run(ctx context.Context, rollupConfigs []blockscout.RollupConfig, l1RPCURL string, l1BeaconURL string) error
 	}
 	outputGenerator interface {
-		Generate(context.Context, map[configs.L2ChainName]map[contracts.ContractName]common.Address) error
+		Generate(context.Context, configs.L2, map[configs.L2ChainName]map[contracts.ContractName]common.Address) error
 	}
 
 	Service struct {
 		rootDir               string
 		cloner                cloner
+		l1Preflight           l1Preflight
 		l1Orchestrator        l1Orchestrator
 		l2ConfigOrchestrator  l2ConfigOrchestrator
 		l2RuntimeOrchestrator l2RuntimeOrchestrator
 		blockscoutService     blockscoutService
 		outputGenerator       outputGenerator
+		checkpointStore       *checkpoint.Store
 		logger                *slog.Logger
 	}
 )
@@ -53,56 +61,118 @@ type (
 func NewService(
 	rootDir string,
 	cloner cloner,
+	l1Preflight l1Preflight,
 	l1Orchestrator l1Orchestrator,
 	l2ConfigOrchestrator l2ConfigOrchestrator,
 	l2RuntimeOrchestrator l2RuntimeOrchestrator,
 	blockscoutService blockscoutService,
-	outputGenerator outputGenerator) *Service {
+	outputGenerator outputGenerator,
+	checkpointStore *checkpoint.Store) *Service {
 	return &Service{
 		rootDir:               rootDir,
 		cloner:                cloner,
+		l1Preflight:           l1Preflight,
 		l1Orchestrator:        l1Orchestrator,
 		l2ConfigOrchestrator:  l2ConfigOrchestrator,
 		l2RuntimeOrchestrator: l2RuntimeOrchestrator,
 		blockscoutService:     blockscoutService,
 		outputGenerator:       outputGenerator,
+		checkpointStore:       checkpointStore,
 		logger:                logger.Named("l2_service"),
 	}
 }
 
-func (s *Service) Deploy(ctx context.Context, cfg configs.L2) error {
+// DeployOptions controls how an L2 deployment reuses the progress of a previous run.
+type DeployOptions struct {
+	// Resume skips phases recorded as completed in the checkpoint file.
+	Resume bool
+	// FromPhase forces the given phase, and every phase after it, to run again. Implies Resume.
+	FromPhase checkpoint.Phase
+	// PortMode selects whether host ports in use fail the deployment or are replaced by free ones.
+	PortMode ports.Mode
+	// Yes proceeds with the L1 deployment when the preflight finds an unknown L1 chain or insufficient funds.
+	Yes bool
+	// Locked pins repositories and images to the lock file instead of resolving and recording them.
+	Locked bool
+	// Offline uses the existing clones and images instead of fetching, pulling or building them.
+	Offline bool
+	// Rebuild builds the local images even if they were built from the checked out sources.
+	Rebuild bool
+}
+
+func (s *Service) Deploy(ctx context.Context, cfg configs.L2, opts DeployOptions) error {
 	s.logger.Info("starting L2 deployment process")
 
-	if err := s.cloneRepositories(ctx, cfg); err != nil {
-		return fmt.Errorf("failed to clone repositories: %w", err)
+	cp, err := s.loadCheckpoint(cfg, opts)
+	if err != nil {
+		return err
 	}
 
-	s.logger.Info("running phase 1 - L1 deployments")
-	deploymentState, err := s.l1Orchestrator.Execute(ctx, cfg)
-	if err != nil {
-		return fmt.Errorf("phase 1 failed: %w", err)
+	if err := s.runPhase(&cp, checkpoint.PhaseClone, func() error {
+		if err := s.cloneRepositories(ctx, cfg); err != nil {
+			return fmt.Errorf("failed to clone repositories: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
 	}
 
-	s.logger.Info("running phase 2 - L2 config generation", "deployment_state", deploymentState)
-	err = s.l2ConfigOrchestrator.Execute(ctx, cfg, deploymentState)
-	if err != nil {
-		return fmt.Errorf("phase 2 failed: %w", err)
+	if err := s.runPhase(&cp, checkpoint.PhaseL1, func() error {
+		if err := s.l1Preflight.Check(ctx, cfg, opts.Yes); err != nil {
+			return err
+		}
+
+		s.logger.Info("running phase 1 - L1 deployments")
+		deploymentState, err := s.l1Orchestrator.Execute(ctx, cfg)
+		if err != nil {
+			return fmt.Errorf("phase 1 failed: %w", err)
+		}
+		cp.DeploymentState = &deploymentState
+		return nil
+	}); err != nil {
+		return err
 	}
+	if cp.DeploymentState == nil {
+		return fmt.Errorf("checkpoint %s has no L1 deployment state. Rerun with --from-phase=%s", s.checkpointStore.Path(), checkpoint.PhaseL1)
+	}
+	deploymentState := *cp.DeploymentState
 
-	s.logger.Info("running phase 3 - L2 launch")
-	deployedContracts, err := s.l2RuntimeOrchestrator.Execute(ctx, cfg, deploymentState.DisputeGameFactoryAddress)
-	if err != nil {
-		return fmt.Errorf("phase 3 failed: %w", err)
+	if err := s.runPhase(&cp, checkpoint.PhaseL2Config, func() error {
+		s.logger.Info("running phase 2 - L2 config generation", "deployment_state", deploymentState)
+		if err := s.l2ConfigOrchestrator.Execute(ctx, cfg, deploymentState); err != nil {
+			return fmt.Errorf("phase 2 failed: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
 	}
 
-	s.logger.Info("restarting op-geth services to apply mailbox configuration")
-	if err := s.restartOpGeth(ctx); err != nil {
-		const msg = "failed to restart op-geth services"
-		s.logger.Error(msg, "error", err)
-		return fmt.Errorf("%s: %w", msg, err)
+	if err := s.runPhase(&cp, checkpoint.PhaseL2Runtime, func() error {
+		s.logger.Info("running phase 3 - L2 launch")
+		deployedContracts, err := s.l2RuntimeOrchestrator.Execute(ctx, cfg, deploymentState.DisputeGameFactoryAddress)
+		if err != nil {
+			return fmt.Errorf("phase 3 failed: %w", err)
+		}
+
+		s.logger.Info("restarting op-geth services to apply mailbox configuration")
+		if err := s.restartOpGeth(ctx, cfg.ChainNames()); err != nil {
+			const msg = "failed to restart op-geth services"
+			s.logger.Error(msg, "error", err)
+			return fmt.Errorf("%s: %w", msg, err)
+		}
+
+		cp.DeployedContracts = deployedContracts
+		return nil
+	}); err != nil {
+		return err
 	}
 
-	if cfg.Blockscout.Enabled {
+	if err := s.runPhase(&cp, checkpoint.PhaseBlockscout, func() error {
+		if !cfg.Blockscout.Enabled {
+			s.logger.Info("Blockscout is disabled. Skipping Blockscout services")
+			return nil
+		}
+
 		s.logger.Info("blockscout is enabled. Starting Blockscout services")
 		chainConfigs, err := generateBlockscoutConfig(cfg, deploymentState)
 		if err != nil {
@@ -112,43 +182,78 @@ func (s *Service) Deploy(ctx context.Context, cfg configs.L2) error {
 		if err := s.blockscoutService.Run(ctx, chainConfigs, cfg.L1ElURL, cfg.L1ClURL); err != nil {
 			return fmt.Errorf("failed to start Blockscout service: %w", err)
 		}
-	} else {
-		s.logger.Info("Blockscout is disabled. Skipping Blockscout services")
+		return nil
+	}); err != nil {
+		return err
 	}
 
-	s.logger.Info("L2 deployment completed successfully. Generating output file")
+	if err := s.runPhase(&cp, checkpoint.PhaseOutput, func() error {
+		s.logger.Info("L2 deployment completed successfully. Generating output file")
+		if err := s.outputGenerator.Generate(ctx, cfg, cp.DeployedContracts); err != nil {
+			return fmt.Errorf("failed to generate output file: %w", err)
+		}
+		s.logger.Info("output file generated successfully")
+		return nil
+	}); err != nil {
+		return err
+	}
 
-	if err := s.outputGenerator.Generate(ctx, deployedContracts); err != nil {
-		return fmt.Errorf("failed to generate output file: %w", err)
+	return nil
+}
+
+// loadCheckpoint returns the checkpoint to continue from. Without resume options a fresh checkpoint is started.
+func (s *Service) loadCheckpoint(cfg configs.L2, opts DeployOptions) (checkpoint.Checkpoint, error) {
+	if !opts.Resume && opts.FromPhase == "" {
+		return checkpoint.New(cfg), nil
+	}
+
+	cp, found, err := s.checkpointStore.Load()
+	if err != nil {
+		return checkpoint.Checkpoint{}, err
+	}
+	if !found {
+		s.logger.With("path", s.checkpointStore.Path()).Warn("no checkpoint found. Running all phases")
+		return checkpoint.New(cfg), nil
 	}
 
-	s.logger.Info("output file generated successfully")
+	if cp.ConfigFingerprint != checkpoint.Fingerprint(cfg) {
+		return checkpoint.Checkpoint{}, fmt.Errorf("checkpoint %s was created with a different L2 configuration. Rerun without --resume to start over", s.checkpointStore.Path())
+	}
 
-	return nil
+	if opts.FromPhase != "" {
+		s.logger.With("phase", opts.FromPhase).Info("forcing phase and all following phases to run again")
+		cp.InvalidateFrom(opts.FromPhase)
+	}
+
+	return cp, nil
 }
 
-func generateBlockscoutConfig(cfg configs.L2, deploymentState l1deployment.DeploymentState) ([]blockscout.RollupConfig, error) {
-	chainOrder := []configs.L2ChainName{
-		configs.L2ChainNameRollupA,
-		configs.L2ChainNameRollupB,
+// runPhase executes fn unless the phase is already completed, and persists the checkpoint on success.
+func (s *Service) runPhase(cp *checkpoint.Checkpoint, phase checkpoint.Phase, fn func() error) error {
+	if cp.IsCompleted(phase) {
+		s.logger.With("phase", phase, "completed_at", cp.Completed[phase]).Info("phase already completed. Skipping")
+		return nil
 	}
-	chainConfigs := make([]blockscout.RollupConfig, 0, len(chainOrder))
 
-	for _, chainName := range chainOrder {
-		config, ok := cfg.ChainConfigs[chainName]
-		if !ok {
-			return nil, fmt.Errorf("chain config not found for %s", chainName)
-		}
+	if err := fn(); err != nil {
+		return err
+	}
 
-		var hostName string
-		switch chainName {
-		case configs.L2ChainNameRollupA:
-			hostName = "op-geth-a"
-		case configs.L2ChainNameRollupB:
-			hostName = "op-geth-b"
-		default:
-			return nil, fmt.Errorf("unknown chain name: %s", chainName)
-		}
+	cp.MarkCompleted(phase)
+	if err := s.checkpointStore.Save(*cp); err != nil {
+		return fmt.Errorf("failed to persist checkpoint after phase %s: %w", phase, err)
+	}
+
+	return nil
+}
+
+func generateBlockscoutConfig(cfg configs.L2, deploymentState l1deployment.DeploymentState) ([]blockscout.RollupConfig, error) {
+	chainNames := cfg.ChainNames()
+	chainConfigs := make([]blockscout.RollupConfig, 0, len(chainNames))
+
+	for _, chainName := range chainNames {
+		config := cfg.ChainConfigs[chainName]
+		hostName := docker.OpGethService(chainName)
 
 		systemConfigAddr, ok := deploymentState.SystemConfigProxyAddresses[chainName]
 		if !ok {
@@ -161,6 +266,7 @@ func generateBlockscoutConfig(cfg configs.L2, deploymentState l1deployment.Deplo
 			ELHostName:            hostName,
 			RPCPort:               8545,
 			WSPort:                8546,
+			PublicPort:            blockscout.PublicPort(cfg, chainName),
 			SystemConfigProxyAddr: systemConfigAddr,
 		})
 	}
@@ -169,11 +275,12 @@ func generateBlockscoutConfig(cfg configs.L2, deploymentState l1deployment.Deplo
 }
 
 // restartOpGeth restarts op-geth services to pick up new mailbox configuration
-func (s *Service) restartOpGeth(ctx context.Context) error {
-	localnetDir := filepath.Join(s.rootDir, localnetDirName)
+func (s *Service) restartOpGeth(ctx context.Context, chains []configs.L2ChainName) error {
+	localnetDir := filepath.Join(s.rootDir, configs.WorkDir())
 	composeFile := filepath.Join(localnetDir, "docker-compose.yml")
 
-	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", composeFile, "restart", "op-geth-a", "op-geth-b")
+	args := append([]string{"compose", "-f", composeFile, "restart"}, docker.OpGethServices(chains)...)
+	cmd := exec.CommandContext(ctx, "docker", args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("docker compose restart failed: %w, output: %s", err, string(output))
 	}
@@ -187,9 +294,10 @@ func (s *Service) restartOpGeth(ctx context.Context) error {
 func (s *Service) cloneRepositories(ctx context.Context, cfg configs.L2) error {
 	s.logger.Info("cloning required repositories")
 
-	repos := make([]git.Repository, 0, len(cfg.Repositories))
+	sourceRepositories := cfg.SourceRepositories()
+	repos := make([]git.Repository, 0, len(sourceRepositories))
 
-	for name, repo := range cfg.Repositories {
+	for name, repo := range sourceRepositories {
 		if repo.URL != "" {
 			repos = append(repos, git.Repository{
 				Name: string(name),
@@ -211,7 +319,7 @@ func (s *Service) cloneRepositories(ctx context.Context, cfg configs.L2) error {
 		return fmt.Errorf("repository %s has neither URL nor local-path set", name)
 	}
 
-	l2Dir := filepath.Join(s.rootDir, localnetDirName, servicesDirName)
+	l2Dir := filepath.Join(s.rootDir, configs.WorkDir(), servicesDirName)
 	if err := s.cloner.CloneAll(ctx, l2Dir, repos); err != nil {
 		return fmt.Errorf("failed to clone repositories: %w", err)
 	}
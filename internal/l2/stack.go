@@ -0,0 +1,170 @@
+package l2
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/compose-network/local-testnet/configs"
+	dockerclient "github.com/compose-network/local-testnet/internal/docker"
+	"github.com/compose-network/local-testnet/internal/l2/infra/docker"
+	"github.com/spf13/cobra"
+)
+
+const (
+	cleanImagesFlag = "images"
+	cleanClonesFlag = "clones"
+	cleanAllFlag    = "all"
+)
+
+// generatedArtifacts are the files and directories in the work directory produced by a deployment.
+// Cloned repositories (services) are handled separately, since re-cloning is slow.
+var generatedArtifacts = []string{
+	stateDirName,
+	networksDirName,
+	compiledContractsDirName,
+	planDirName,
+	"registry",
+	".tmp",
+	".env",
+	"docker-compose.yml",
+	"docker-compose.flashblocks.yml",
+	"docker-compose.sidecar.yml",
+	"docker-compose.blockscout.yml",
+}
+
+var downCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Stop and remove L2 containers, keeping volumes and generated configs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDockerClient(func(client *dockerclient.Client) error {
+			return removeContainers(cmd.Context(), client)
+		})
+	},
+}
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove L2 containers, volumes, networks and generated configs",
+	Long: `Removes every L2 container, volume and network of the selected instance (labeled stack=localnet-l2,
+or stack=<instance>-localnet-l2) together with the generated files in its work directory (.localnet,
+or .localnet-<instance>). Cloned repositories and locally built images are kept unless --clones,
+--images or --all is given. Locally built images are shared by all instances.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool(cleanAllFlag)
+		removeImages, _ := cmd.Flags().GetBool(cleanImagesFlag)
+		removeClones, _ := cmd.Flags().GetBool(cleanClonesFlag)
+		removeImages = removeImages || all
+		removeClones = removeClones || all
+
+		rootDir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %w", err)
+		}
+		localnetDir := filepath.Join(rootDir, configs.WorkDir())
+
+		ctx := cmd.Context()
+		err = withDockerClient(func(client *dockerclient.Client) error {
+			if err := removeContainers(ctx, client); err != nil {
+				return err
+			}
+
+			volumes, err := client.RemoveStackVolumes(ctx, dockerclient.L2Stack())
+			if err != nil {
+				return err
+			}
+			slog.With("count", volumes).Info("L2 volumes removed")
+
+			networks, err := client.RemoveStackNetworks(ctx, dockerclient.L2Stack())
+			if err != nil {
+				return err
+			}
+			slog.With("count", networks).Info("L2 networks removed")
+
+			if removeImages {
+				images, err := client.RemoveImages(ctx, docker.LocalImages...)
+				if err != nil {
+					return err
+				}
+				slog.With("count", images).Info("locally built L2 images removed")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		paths := make([]string, 0, len(generatedArtifacts)+2)
+		for _, name := range generatedArtifacts {
+			paths = append(paths, filepath.Join(localnetDir, name))
+		}
+		paths = append(paths, filepath.Join(rootDir, ".cache"))
+		if removeClones {
+			paths = append(paths, filepath.Join(localnetDir, servicesDirName))
+		}
+
+		for _, path := range paths {
+			if err := os.RemoveAll(path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+		}
+
+		slog.With("images_removed", removeImages, "clones_removed", removeClones).Info("L2 cleanup completed")
+		return nil
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state and health of every L2 service",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withDockerClient(func(client *dockerclient.Client) error {
+			statuses, err := client.StackContainers(cmd.Context(), dockerclient.L2Stack())
+			if err != nil {
+				return err
+			}
+
+			if len(statuses) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "no L2 services found")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SERVICE\tCONTAINER\tSTATE\tHEALTH\tSTATUS")
+			for _, status := range statuses {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", status.Service, status.Name, status.State, status.Health, status.Status)
+			}
+			return w.Flush()
+		})
+	},
+}
+
+func init() {
+	cleanCmd.Flags().Bool(cleanImagesFlag, false, "Also remove locally built images (local/*:dev)")
+	cleanCmd.Flags().Bool(cleanClonesFlag, false, "Also remove cloned repositories in <work dir>/services")
+	cleanCmd.Flags().Bool(cleanAllFlag, false, "Wipe everything: containers, volumes, configs, images and clones")
+}
+
+// withDockerClient runs fn with a Docker client that is closed afterwards.
+func withDockerClient(fn func(client *dockerclient.Client) error) error {
+	client, err := dockerclient.New()
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer client.Close()
+
+	return fn(client)
+}
+
+func removeContainers(ctx context.Context, client *dockerclient.Client) error {
+	count, err := client.RemoveStackContainers(ctx, dockerclient.L2Stack())
+	if err != nil {
+		return err
+	}
+	slog.With("count", count).Info("L2 containers removed")
+	return nil
+}
@@ -0,0 +1,103 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the lock file, written next to the config file of an instance.
+const FileName = "localnet.lock"
+
+// header explains the file to readers of a checked in lock.
+const header = "# Written by localnet after every successful run. Run with --locked to reuse exactly these versions.\n"
+
+type (
+	// File records the versions a successful run resolved, so a later run can reproduce them.
+	File struct {
+		// Repositories are keyed by repository name, e.g. "op-geth".
+		Repositories map[string]Repository `yaml:"repositories,omitempty"`
+		// Images map an image reference as configured, e.g. "nginx:1.29.3-alpine", to its digest
+		// reference, e.g. "nginx@sha256:...".
+		Images map[string]string `yaml:"images,omitempty"`
+		// Kurtosis is the package run by the Kurtosis L1 backend.
+		Kurtosis *Package `yaml:"kurtosis,omitempty"`
+	}
+
+	// Repository is a git repository resolved to a commit.
+	Repository struct {
+		URL    string `yaml:"url"`
+		Ref    string `yaml:"ref,omitempty"`
+		Commit string `yaml:"commit"`
+	}
+
+	// Package is a Kurtosis package resolved to a commit.
+	Package struct {
+		Name   string `yaml:"name"`
+		Commit string `yaml:"commit"`
+	}
+)
+
+// Image returns the digest reference pinned for ref, or ref itself when the file does not pin it.
+func (f File) Image(ref string) string {
+	if pinned, ok := f.Images[ref]; ok {
+		return pinned
+	}
+	return ref
+}
+
+// Load reads a lock file. A missing file yields an empty file and found set to false.
+func Load(path string) (f File, found bool, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return File{}, false, nil
+	}
+	if err != nil {
+		return File{}, false, fmt.Errorf("failed to read lock file %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return File{}, false, fmt.Errorf("failed to parse lock file %s: %w", path, err)
+	}
+	return f, true, nil
+}
+
+// Record merges f into the lock file, keeping the entries recorded by other commands.
+func Record(path string, f File) error {
+	recorded, _, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	if len(f.Repositories) > 0 {
+		if recorded.Repositories == nil {
+			recorded.Repositories = make(map[string]Repository, len(f.Repositories))
+		}
+		maps.Copy(recorded.Repositories, f.Repositories)
+	}
+	if len(f.Images) > 0 {
+		if recorded.Images == nil {
+			recorded.Images = make(map[string]string, len(f.Images))
+		}
+		maps.Copy(recorded.Images, f.Images)
+	}
+	if f.Kurtosis != nil {
+		recorded.Kurtosis = f.Kurtosis
+	}
+
+	data, err := yaml.Marshal(recorded)
+	if err != nil {
+		return fmt.Errorf("failed to encode lock file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for lock file: %w", err)
+	}
+	if err := os.WriteFile(path, append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write lock file %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fanoutHandler sends every record to all of its handlers.
+type fanoutHandler []slog.Handler
+
+func (h fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanoutHandler, 0, len(h))
+	for _, handler := range h {
+		handlers = append(handlers, handler.WithAttrs(attrs))
+	}
+	return handlers
+}
+
+func (h fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make(fanoutHandler, 0, len(h))
+	for _, handler := range h {
+		handlers = append(handlers, handler.WithGroup(name))
+	}
+	return handlers
+}
+
+// prettyHandler renders records as single human-readable lines:
+//
+//	15:04:05.000 INF [component] message key=value ...
+//
+// Top-level "name" and "component" attributes are pulled to the front, levels are colored on terminals.
+type prettyHandler struct {
+	w     io.Writer
+	mu    *sync.Mutex
+	level slog.Level
+	color bool
+	group string
+	// attrs are the attributes added through WithAttrs, with keys already qualified by their group.
+	attrs []slog.Attr
+}
+
+const (
+	colorReset  = "\033[0m"
+	colorGray   = "\033[90m"
+	colorCyan   = "\033[36m"
+	colorYellow = "\033[33m"
+	colorRed    = "\033[31m"
+)
+
+func newPrettyHandler(w io.Writer, level slog.Level) *prettyHandler {
+	return &prettyHandler{w: w, mu: &sync.Mutex{}, level: level, color: isTerminal(w)}
+}
+
+func (h *prettyHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *prettyHandler) Handle(_ context.Context, record slog.Record) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(h.paint(colorGray, record.Time.Format(time.TimeOnly+".000")))
+	buf.WriteByte(' ')
+	buf.WriteString(h.levelLabel(record.Level))
+	buf.WriteByte(' ')
+
+	prefix := ""
+	rest := make([]slog.Attr, 0, len(h.attrs)+record.NumAttrs())
+	collect := func(attr slog.Attr) {
+		attr.Value = attr.Value.Resolve()
+		if attr.Key == "name" || attr.Key == "component" {
+			prefix = attr.Value.String()
+			return
+		}
+		rest = append(rest, attr)
+	}
+	for _, attr := range h.attrs {
+		collect(attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		collect(qualify(h.group, attr))
+		return true
+	})
+
+	if prefix != "" {
+		buf.WriteString(h.paint(colorCyan, "["+prefix+"]"))
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(record.Message)
+
+	for _, attr := range rest {
+		writeAttr(&buf, "", attr)
+	}
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+func (h *prettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, attr := range attrs {
+		clone.attrs = append(clone.attrs, qualify(h.group, attr))
+	}
+	return &clone
+}
+
+func (h *prettyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	if clone.group != "" {
+		name = clone.group + "." + name
+	}
+	clone.group = name
+	return &clone
+}
+
+func (h *prettyHandler) levelLabel(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return h.paint(colorRed, "ERR")
+	case level >= slog.LevelWarn:
+		return h.paint(colorYellow, "WRN")
+	case level >= slog.LevelInfo:
+		return "INF"
+	default:
+		return h.paint(colorGray, "DBG")
+	}
+}
+
+func (h *prettyHandler) paint(color, text string) string {
+	if !h.color {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// qualify prefixes the attribute key with the group path.
+func qualify(group string, attr slog.Attr) slog.Attr {
+	if group != "" {
+		attr.Key = group + "." + attr.Key
+	}
+	return attr
+}
+
+func writeAttr(buf *bytes.Buffer, group string, attr slog.Attr) {
+	key := attr.Key
+	if group != "" {
+		key = group + "." + key
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, child := range attr.Value.Group() {
+			child.Value = child.Value.Resolve()
+			writeAttr(buf, key, child)
+		}
+		return
+	}
+
+	value := attr.Value.String()
+	if attr.Value.Kind() == slog.KindAny {
+		value = fmt.Sprintf("%+v", attr.Value.Any())
+	}
+	if value == "" || strings.ContainsAny(value, " \t\"=") {
+		value = fmt.Sprintf("%q", value)
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	buf.WriteString(value)
+}
+
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"log/slog"
+	"maps"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	// Redacted replaces secret values in logs.
+	Redacted = "[REDACTED]"
+	// redactedURLPart replaces secrets inside URLs, where brackets would be percent-encoded.
+	redactedURLPart = "REDACTED"
+)
+
+// secretMarkers are name fragments (upper-cased, '-' normalized to '_') that identify secret values.
+var secretMarkers = []string{"PRIVATE_KEY", "_PK", "SECRET", "PASSWORD", "TOKEN", "MNEMONIC", "API_KEY", "APIKEY"}
+
+// Env is an environment variable map whose secret values are redacted when logged.
+type Env map[string]string
+
+// LogValue implements slog.LogValuer.
+func (e Env) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(e))
+	for _, key := range slices.Sorted(maps.Keys(e)) {
+		attrs = append(attrs, slog.String(key, RedactValue(key, e[key])))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// IsSecret reports whether a variable or config key names a secret.
+func IsSecret(name string) bool {
+	normalized := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	for _, marker := range secretMarkers {
+		if strings.Contains(normalized, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactValue hides the value of secret keys and credentials embedded in URLs.
+func RedactValue(key, value string) string {
+	if value == "" {
+		return value
+	}
+	if IsSecret(key) {
+		return Redacted
+	}
+	return RedactURL(value)
+}
+
+// RedactURL hides the password of the user info and secret query parameters of a URL. On public hosts the
+// path segments after a version segment are hidden too, as RPC providers put their API key there
+// (e.g. https://sepolia.infura.io/v3/<key>). Values that are not absolute URLs are returned unchanged.
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return raw
+	}
+
+	changed := false
+	if !isLocalHost(u.Hostname()) {
+		if path, redacted := redactPathAfterVersion(u.Path); redacted {
+			u.Path, u.RawPath = path, ""
+			changed = true
+		}
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), redactedURLPart)
+		changed = true
+	}
+
+	query := u.Query()
+	for key := range query {
+		if IsSecret(key) || strings.EqualFold(key, "key") {
+			query.Set(key, redactedURLPart)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return raw
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// redactPathAfterVersion replaces the non-empty segments following the first version segment (v1, v2, ...).
+func redactPathAfterVersion(path string) (string, bool) {
+	segments := strings.Split(path, "/")
+	version := slices.IndexFunc(segments, func(segment string) bool {
+		return len(segment) > 1 && segment[0] == 'v' && strings.Trim(segment[1:], "0123456789") == ""
+	})
+	if version < 0 {
+		return path, false
+	}
+
+	redacted := false
+	for i := version + 1; i < len(segments); i++ {
+		if segments[i] != "" {
+			segments[i] = redactedURLPart
+			redacted = true
+		}
+	}
+	return strings.Join(segments, "/"), redacted
+}
+
+// isLocalHost reports whether host is reached without a provider: loopback and private addresses, Docker
+// service names (no dot) and host names of the local machine or Docker host.
+func isLocalHost(host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified()
+	}
+	host = strings.ToLower(host)
+	return host == "localhost" || !strings.Contains(host, ".") ||
+		strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal")
+}
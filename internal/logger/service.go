@@ -1,16 +1,90 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
-func Initialize(level slog.Level) {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
-	}))
+// Format selects how log records are rendered on stderr.
+type Format string
 
-	slog.SetDefault(logger)
+const (
+	FormatJSON   Format = "json"
+	FormatText   Format = "text"
+	FormatPretty Format = "pretty"
+)
+
+// Options configures the process-wide logger.
+type Options struct {
+	Level  slog.Level
+	Format Format
+	// File, when set, receives every record as JSON in addition to stderr.
+	File string
+}
+
+// logFile is the optional file sink, closed by Close.
+var logFile *os.File
+
+// ParseFormat converts a user supplied format name into a Format.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(name))); format {
+	case FormatJSON, FormatText, FormatPretty:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown log format '%s' (expected one of: %s, %s, %s)", name, FormatJSON, FormatText, FormatPretty)
+	}
+}
+
+// ParseLevel converts a user supplied level name (debug, info, warn, error) into a slog.Level.
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return 0, fmt.Errorf("unknown log level '%s' (expected one of: debug, info, warn, error)", name)
+	}
+	return level, nil
+}
+
+// Initialize installs the default logger. Records go to stderr so stdout only carries command output,
+// such as the JSON of l1 status or config show.
+func Initialize(opts Options) error {
+	handler := newHandler(os.Stderr, opts.Format, opts.Level)
+
+	if opts.File != "" {
+		if err := Close(); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(opts.File), 0755); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file %s: %w", opts.File, err)
+		}
+		logFile = file
+		handler = fanoutHandler{handler, newHandler(file, FormatJSON, opts.Level)}
+	}
+
+	slog.SetDefault(slog.New(handler))
+
+	return nil
+}
+
+// Close flushes and closes the log file sink, if any.
+func Close() error {
+	if logFile == nil {
+		return nil
+	}
+	err := logFile.Close()
+	logFile = nil
+	if err != nil && !errors.Is(err, os.ErrClosed) {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	return nil
 }
 
 func Named(name string) *slog.Logger {
@@ -21,3 +95,15 @@ func Named(name string) *slog.Logger {
 
 	return logger.With("name", name)
 }
+
+func newHandler(w io.Writer, format Format, level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	switch format {
+	case FormatText:
+		return slog.NewTextHandler(w, opts)
+	case FormatPretty:
+		return newPrettyHandler(w, level)
+	default:
+		return slog.NewJSONHandler(w, opts)
+	}
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// LineWriter turns the output of a child process into log records, one per line,
+// tagged with a "component" attribute so interleaved streams stay distinguishable.
+type LineWriter struct {
+	logger *slog.Logger
+	level  slog.Level
+	mu     sync.Mutex
+	buf    bytes.Buffer
+}
+
+// NewWriter creates a LineWriter logging at info level through the default logger.
+func NewWriter(component string) *LineWriter {
+	return &LineWriter{logger: slog.Default().With("component", component), level: slog.LevelInfo}
+}
+
+// CommandOutput returns writers for the stdout and stderr of a child process.
+// Close both after the process exits to flush a trailing partial line.
+func CommandOutput(component string) (stdout, stderr *LineWriter) {
+	stdout = NewWriter(component)
+	stderr = NewWriter(component)
+	stderr.logger = stderr.logger.With("stream", "stderr")
+	return stdout, stderr
+}
+
+// With adds attributes to every record logged by w, e.g. to tell apart concurrent runs of the same command.
+func (w *LineWriter) With(args ...any) *LineWriter {
+	w.logger = w.logger.With(args...)
+	return w
+}
+
+// Write implements io.Writer.
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// No complete line left, keep the remainder for the next write.
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			break
+		}
+		w.emit(line)
+	}
+
+	return len(p), nil
+}
+
+// Close logs any buffered partial line.
+func (w *LineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.emit(w.buf.String())
+		w.buf.Reset()
+	}
+	return nil
+}
+
+func (w *LineWriter) emit(line string) {
+	// Progress output (docker, git) redraws lines with carriage returns; only the final state matters.
+	if i := strings.LastIndex(strings.TrimRight(line, "\r\n"), "\r"); i >= 0 {
+		line = line[i+1:]
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	w.logger.Log(context.Background(), w.level, line)
+}
@@ -0,0 +1,123 @@
+// Package mnemonic derives Ethereum account keys from BIP-39 mnemonics.
+package mnemonic
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// english is the BIP-39 English word list, one word per line in index order.
+//
+//go:embed english.txt
+var english string
+
+var (
+	wordCounts = []int{12, 15, 18, 21, 24}
+	wordList   = strings.Fields(english)
+)
+
+// DeriveKey derives the key of an account on the standard Ethereum path m/44'/60'/0'/0/<index>
+// from a BIP-39 mnemonic (without passphrase), as wallets, anvil and hardhat do.
+// Only English mnemonics are accepted; their words are ASCII, so NFKD normalization is a no-op.
+func DeriveKey(mnemonic string, index uint32) (*ecdsa.PrivateKey, error) {
+	words := strings.Fields(mnemonic)
+	if err := validate(words); err != nil {
+		return nil, err
+	}
+
+	seed, err := pbkdf2.Key(sha512.New, strings.Join(words, " "), []byte("mnemonic"), 2048, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive seed: %w", err)
+	}
+
+	path := slices.Clone(accounts.DefaultBaseDerivationPath)
+	path[len(path)-1] = index
+
+	key, chainCode := hmacSHA512([]byte("Bitcoin seed"), seed)
+	for _, child := range path {
+		key, chainCode, err = deriveChild(key, chainCode, child)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return crypto.ToECDSA(key)
+}
+
+// validate checks the word count, that every word is in the English word list and the checksum
+// carried by the last word.
+func validate(words []string) error {
+	if !slices.Contains(wordCounts, len(words)) {
+		return fmt.Errorf("mnemonic must have 12, 15, 18, 21 or 24 words, got %d", len(words))
+	}
+
+	// Each word encodes 11 bits: the entropy followed by a checksum of one bit per 32 entropy bits.
+	bits := new(big.Int)
+	for i, word := range words {
+		index, found := slices.BinarySearch(wordList, word)
+		if !found {
+			return fmt.Errorf("mnemonic word %d (%q) is not in the BIP-39 English word list", i+1, word)
+		}
+		bits.Lsh(bits, 11).Or(bits, big.NewInt(int64(index)))
+	}
+
+	checksumBits := uint(len(words) * 11 / 33)
+	checksum := new(big.Int).And(bits, big.NewInt(1<<checksumBits-1)).Uint64()
+	entropy := new(big.Int).Rsh(bits, checksumBits).FillBytes(make([]byte, checksumBits*4))
+
+	hash := sha256.Sum256(entropy)
+	if uint64(hash[0]>>(8-checksumBits)) != checksum {
+		return errors.New("mnemonic checksum is invalid, check the words and their order")
+	}
+
+	return nil
+}
+
+// deriveChild implements BIP-32 private child key derivation.
+func deriveChild(key, chainCode []byte, index uint32) ([]byte, []byte, error) {
+	var data []byte
+	if index >= 0x80000000 {
+		data = append([]byte{0}, key...)
+	} else {
+		private, err := crypto.ToECDSA(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = crypto.CompressPubkey(&private.PublicKey)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	childKey, childChainCode := hmacSHA512(chainCode, data)
+
+	curveOrder := crypto.S256().Params().N
+	tweak := new(big.Int).SetBytes(childKey)
+	if tweak.Cmp(curveOrder) >= 0 {
+		return nil, nil, errors.New("derived key is out of range, use another index")
+	}
+	tweak.Add(tweak, new(big.Int).SetBytes(key)).Mod(tweak, curveOrder)
+	if tweak.Sign() == 0 {
+		return nil, nil, errors.New("derived key is zero, use another index")
+	}
+
+	return tweak.FillBytes(make([]byte, 32)), childChainCode, nil
+}
+
+func hmacSHA512(key, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
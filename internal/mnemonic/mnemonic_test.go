@@ -0,0 +1,66 @@
+package mnemonic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func TestDeriveKey(t *testing.T) {
+	tests := []struct {
+		index   uint32
+		address string
+	}{
+		{index: 0, address: "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"},
+		{index: 1, address: "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"},
+	}
+	for _, tt := range tests {
+		key, err := DeriveKey(testMnemonic, tt.index)
+		if err != nil {
+			t.Fatalf("DeriveKey(%d) failed: %v", tt.index, err)
+		}
+		if got := crypto.PubkeyToAddress(key.PublicKey).Hex(); got != tt.address {
+			t.Errorf("DeriveKey(%d) = %s, want %s", tt.index, got, tt.address)
+		}
+	}
+}
+
+func TestDeriveKeyRejectsInvalidMnemonics(t *testing.T) {
+	tests := []struct {
+		name     string
+		mnemonic string
+		wantErr  string
+	}{
+		{name: "word count", mnemonic: "test test test", wantErr: "must have"},
+		{name: "unknown word", mnemonic: strings.Replace(testMnemonic, "junk", "junky", 1), wantErr: "word 12"},
+		{name: "checksum", mnemonic: strings.Replace(testMnemonic, "junk", "just", 1), wantErr: "checksum"},
+		{name: "word order", mnemonic: "junk test test test test test test test test test test test", wantErr: "checksum"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DeriveKey(tt.mnemonic, 0)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("DeriveKey() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAcceptsReferenceVectors(t *testing.T) {
+	// From the BIP-39 reference test vectors.
+	for _, mnemonic := range []string{
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon " +
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+	} {
+		if err := validate(strings.Fields(mnemonic)); err != nil {
+			t.Errorf("validate(%q) failed: %v", mnemonic, err)
+		}
+	}
+}
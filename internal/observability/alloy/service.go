@@ -8,18 +8,29 @@ import (
 	"path/filepath"
 
 	"github.com/compose-network/local-testnet/internal/observability/shared"
+	"github.com/compose-network/local-testnet/internal/ports"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
-	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/strslice"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 )
 
-const dockerImage = "grafana/alloy:v1.9.1"
+const (
+	dockerImage = "grafana/alloy:v1.9.1"
+	serviceName = "alloy"
+)
+
+// containerPorts are published on the host, by default on the same port numbers.
+var containerPorts = []int{12345, 4317, 4318}
 
-func Start(ctx context.Context, client *client.Client) error {
+// HostPorts lists the host ports published by alloy, with their defaults.
+func HostPorts() []ports.Port {
+	return shared.HostPorts(serviceName, containerPorts...)
+}
+
+func Start(ctx context.Context, client *client.Client, hostPorts ports.Map) error {
 	logger := slog.With("observability_service_name", "alloy")
 
 	reader, err := client.ImagePull(ctx, dockerImage, image.PullOptions{})
@@ -47,16 +58,12 @@ func Start(ctx context.Context, client *client.Client) error {
 			"4317/tcp":  {},
 			"4318/tcp":  {},
 		},
-		Labels: shared.Labels,
+		Labels: shared.Labels(),
 		Cmd:    strslice.StrSlice{"run", "--server.http.listen-addr=0.0.0.0:12345", "--storage.path=/var/lib/alloy/data", "/etc/alloy/config.alloy"},
 	},
 		&container.HostConfig{
-			NetworkMode: shared.NetworkMode,
-			PortBindings: nat.PortMap{
-				"12345/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "12345"}},
-				"4317/tcp":  []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "4317"}},
-				"4318/tcp":  []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "4318"}},
-			},
+			NetworkMode:  shared.NetworkMode(),
+			PortBindings: shared.PortBindings(serviceName, hostPorts, containerPorts...),
 			Mounts: []mount.Mount{
 				{
 					Type:   mount.TypeBind,
@@ -70,9 +77,9 @@ func Start(ctx context.Context, client *client.Client) error {
 				},
 			},
 		},
-		&network.NetworkingConfig{},
+		shared.NetworkingConfig(serviceName),
 		nil,
-		"alloy")
+		shared.ContainerName(serviceName))
 	if err != nil {
 		return errors.Join(err, errors.New("failed to create container"))
 	}
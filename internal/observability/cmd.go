@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/compose-network/local-testnet/configs"
 	"github.com/spf13/cobra"
 )
 
@@ -13,7 +14,7 @@ var CMD = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		slog.Info("Starting observability services")
 
-		if err := start(cmd.Context()); err != nil {
+		if err := Start(cmd.Context(), configs.Values.Ports.Mode); err != nil {
 			return fmt.Errorf("error occurred starting observability services: %w", err)
 		}
 
@@ -8,17 +8,28 @@ import (
 	"path/filepath"
 
 	"github.com/compose-network/local-testnet/internal/observability/shared"
+	"github.com/compose-network/local-testnet/internal/ports"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
-	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 )
 
-const dockerImage = "grafana/grafana:12.0.1"
+const (
+	dockerImage = "grafana/grafana:12.0.1"
+	serviceName = "grafana"
+)
+
+// containerPorts are published on the host, by default on the same port numbers.
+var containerPorts = []int{3000}
 
-func Start(ctx context.Context, client *client.Client) error {
+// HostPorts lists the host ports published by grafana, with their defaults.
+func HostPorts() []ports.Port {
+	return shared.HostPorts(serviceName, containerPorts...)
+}
+
+func Start(ctx context.Context, client *client.Client, hostPorts ports.Map) error {
 	logger := slog.With("observability_service_name", "grafana")
 
 	reader, err := client.ImagePull(ctx, dockerImage, image.PullOptions{})
@@ -48,13 +59,11 @@ func Start(ctx context.Context, client *client.Client) error {
 		ExposedPorts: nat.PortSet{
 			"3000/tcp": struct{}{},
 		},
-		Labels: shared.Labels,
+		Labels: shared.Labels(),
 	},
 		&container.HostConfig{
-			NetworkMode: shared.NetworkMode,
-			PortBindings: nat.PortMap{
-				"3000/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "3000"}},
-			},
+			NetworkMode:  shared.NetworkMode(),
+			PortBindings: shared.PortBindings(serviceName, hostPorts, containerPorts...),
 			Mounts: []mount.Mount{
 				{
 					Type:   mount.TypeBind,
@@ -63,9 +72,9 @@ func Start(ctx context.Context, client *client.Client) error {
 				},
 			},
 		},
-		&network.NetworkingConfig{},
+		shared.NetworkingConfig(serviceName),
 		nil,
-		"grafana")
+		shared.ContainerName(serviceName))
 	if err != nil {
 		return errors.Join(err, errors.New("failed to create container"))
 	}
@@ -8,18 +8,29 @@ import (
 	"path/filepath"
 
 	"github.com/compose-network/local-testnet/internal/observability/shared"
+	"github.com/compose-network/local-testnet/internal/ports"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
-	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/strslice"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 )
 
-const dockerImage = "prom/prometheus:v3.4.1"
+const (
+	dockerImage = "prom/prometheus:v3.4.1"
+	serviceName = "prometheus"
+)
+
+// containerPorts are published on the host, by default on the same port numbers.
+var containerPorts = []int{9090}
 
-func Start(ctx context.Context, client *client.Client) error {
+// HostPorts lists the host ports published by prometheus, with their defaults.
+func HostPorts() []ports.Port {
+	return shared.HostPorts(serviceName, containerPorts...)
+}
+
+func Start(ctx context.Context, client *client.Client, hostPorts ports.Map) error {
 	logger := slog.With("observability_service_name", "prometheus")
 
 	reader, err := client.ImagePull(ctx, dockerImage, image.PullOptions{})
@@ -45,14 +56,12 @@ func Start(ctx context.Context, client *client.Client) error {
 		ExposedPorts: nat.PortSet{
 			"9090/tcp": struct{}{},
 		},
-		Labels: shared.Labels,
+		Labels: shared.Labels(),
 		Cmd:    strslice.StrSlice{"--config.file=/etc/prometheus/config.yaml", "--web.enable-remote-write-receiver"},
 	},
 		&container.HostConfig{
-			NetworkMode: shared.NetworkMode,
-			PortBindings: nat.PortMap{
-				"9090/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "9090"}},
-			},
+			NetworkMode:  shared.NetworkMode(),
+			PortBindings: shared.PortBindings(serviceName, hostPorts, containerPorts...),
 			Mounts: []mount.Mount{
 				{
 					Type:   mount.TypeBind,
@@ -61,9 +70,9 @@ func Start(ctx context.Context, client *client.Client) error {
 				},
 			},
 		},
-		&network.NetworkingConfig{},
+		shared.NetworkingConfig(serviceName),
 		nil,
-		"prometheus")
+		shared.ContainerName(serviceName))
 	if err != nil {
 		return errors.Join(err, errors.New("failed to create container"))
 	}
@@ -4,17 +4,22 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"path/filepath"
 
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/docker"
 	"github.com/compose-network/local-testnet/internal/observability/alloy"
 	"github.com/compose-network/local-testnet/internal/observability/grafana"
 	"github.com/compose-network/local-testnet/internal/observability/loki"
 	"github.com/compose-network/local-testnet/internal/observability/prometheus"
 	"github.com/compose-network/local-testnet/internal/observability/shared"
 	"github.com/compose-network/local-testnet/internal/observability/tempo"
+	"github.com/compose-network/local-testnet/internal/ports"
 	"github.com/docker/docker/client"
 )
 
-func start(ctx context.Context) error {
+// Start launches the observability services on the shared Docker network.
+func Start(ctx context.Context, portMode ports.Mode) error {
 	slog.Info("instantiating Docker client")
 
 	cli, err := client.NewClientWithOpts(client.WithAPIVersionNegotiation())
@@ -23,30 +28,79 @@ func start(ctx context.Context) error {
 	}
 	defer cli.Close()
 
-	slog.With("network_name", shared.ObservabilityNetworkName).Info("creating new shared Docker network")
+	hostPorts, err := ResolveHostPorts(ctx, portMode)
+	if err != nil {
+		return err
+	}
+
+	slog.With("network_name", shared.ObservabilityNetworkName()).Info("creating new shared Docker network")
 	if err = shared.EnsureNetwork(ctx, cli); err != nil {
 		return errors.Join(err, errors.New("failed to create a Docker network"))
 	}
 
-	if err := grafana.Start(ctx, cli); err != nil {
+	if err := grafana.Start(ctx, cli, hostPorts); err != nil {
 		return errors.Join(err, errors.New("failed to start Grafana service"))
 	}
 
-	if err := loki.Start(ctx, cli); err != nil {
+	if err := loki.Start(ctx, cli, hostPorts); err != nil {
 		return errors.Join(err, errors.New("failed to start Loki service"))
 	}
 
-	if err := alloy.Start(ctx, cli); err != nil {
+	if err := alloy.Start(ctx, cli, hostPorts); err != nil {
 		return errors.Join(err, errors.New("failed to start Alloy service"))
 	}
 
-	if err := prometheus.Start(ctx, cli); err != nil {
+	if err := prometheus.Start(ctx, cli, hostPorts); err != nil {
 		return errors.Join(err, errors.New("failed to start Prometheus service"))
 	}
 
-	if err := tempo.Start(ctx, cli); err != nil {
+	if err := tempo.Start(ctx, cli, hostPorts); err != nil {
 		return errors.Join(err, errors.New("failed to start Tempo service"))
 	}
 
 	return nil
 }
+
+// HostPorts lists the host ports published by the observability services, with their defaults.
+func HostPorts() []ports.Port {
+	var hostPorts []ports.Port
+	for _, servicePorts := range [][]ports.Port{grafana.HostPorts(), loki.HostPorts(), alloy.HostPorts(), prometheus.HostPorts(), tempo.HostPorts()} {
+		hostPorts = append(hostPorts, servicePorts...)
+	}
+	return hostPorts
+}
+
+// ResolveHostPorts checks (fixed mode) or assigns (auto mode) the host ports of the observability
+// services and records them in the port map file.
+func ResolveHostPorts(ctx context.Context, portMode ports.Mode) (ports.Map, error) {
+	requested := HostPorts()
+
+	path := filepath.Join(configs.WorkDir(), ports.FileName)
+	previous, err := ports.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dockerClient, err := docker.New()
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to instantiate Docker client"))
+	}
+	defer dockerClient.Close()
+
+	owned, err := dockerClient.PublishedPorts(ctx, docker.ObservabilityStack())
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := ports.Resolve(requested, portMode, previous, owned)
+	if err != nil {
+		return nil, err
+	}
+	if err := ports.Record(path, resolved); err != nil {
+		return nil, err
+	}
+
+	slog.With("host_ports", resolved).Info("observability host ports resolved")
+
+	return resolved, nil
+}
@@ -11,9 +11,9 @@ import (
 
 func EnsureNetwork(ctx context.Context, cli *client.Client) error {
 	args := filters.NewArgs()
-	args.Add("name", ObservabilityNetworkName)
-	args.Add("name", L1NetworkName)
-	args.Add("name", L2NetworkName)
+	args.Add("name", ObservabilityNetworkName())
+	args.Add("name", L1NetworkName())
+	args.Add("name", L2NetworkName())
 
 	networks, err := cli.NetworkList(ctx, network.ListOptions{Filters: args})
 	if err != nil {
@@ -22,21 +22,21 @@ func EnsureNetwork(ctx context.Context, cli *client.Client) error {
 
 	var l1Available, l2Available, observabilityAvailable bool
 	for _, network := range networks {
-		if network.Name == L1NetworkName {
+		if network.Name == L1NetworkName() {
 			l1Available = true
 		}
-		if network.Name == L2NetworkName {
+		if network.Name == L2NetworkName() {
 			l2Available = true
 		}
-		if network.Name == ObservabilityNetworkName {
+		if network.Name == ObservabilityNetworkName() {
 			observabilityAvailable = true
 		}
 	}
 
 	if !l1Available {
-		_, err = cli.NetworkCreate(ctx, L1NetworkName, network.CreateOptions{
+		_, err = cli.NetworkCreate(ctx, L1NetworkName(), network.CreateOptions{
 			Driver: "bridge",
-			Labels: Labels,
+			Labels: Labels(),
 		})
 		if err != nil {
 			return errors.Join(err, errors.New("failed to create L1 network"))
@@ -44,9 +44,9 @@ func EnsureNetwork(ctx context.Context, cli *client.Client) error {
 	}
 
 	if !l2Available {
-		_, err = cli.NetworkCreate(ctx, L2NetworkName, network.CreateOptions{
+		_, err = cli.NetworkCreate(ctx, L2NetworkName(), network.CreateOptions{
 			Driver: "bridge",
-			Labels: Labels,
+			Labels: Labels(),
 		})
 		if err != nil {
 			return errors.Join(err, errors.New("failed to create L2 network"))
@@ -54,9 +54,9 @@ func EnsureNetwork(ctx context.Context, cli *client.Client) error {
 	}
 
 	if !observabilityAvailable {
-		_, err = cli.NetworkCreate(ctx, ObservabilityNetworkName, network.CreateOptions{
+		_, err = cli.NetworkCreate(ctx, ObservabilityNetworkName(), network.CreateOptions{
 			Driver: "bridge",
-			Labels: Labels,
+			Labels: Labels(),
 		})
 		if err != nil {
 			return errors.Join(err, errors.New("failed to create observability network"))
@@ -69,8 +69,8 @@ func EnsureNetwork(ctx context.Context, cli *client.Client) error {
 // getAvailableLocalnetNetworks returns list of localnet networks that exist
 func getAvailableLocalnetNetworks(ctx context.Context, cli *client.Client) ([]string, error) {
 	args := filters.NewArgs()
-	args.Add("name", L1NetworkName)
-	args.Add("name", L2NetworkName)
+	args.Add("name", L1NetworkName())
+	args.Add("name", L2NetworkName())
 
 	networks, err := cli.NetworkList(ctx, network.ListOptions{Filters: args})
 	if err != nil {
@@ -79,7 +79,7 @@ func getAvailableLocalnetNetworks(ctx context.Context, cli *client.Client) ([]st
 
 	var available []string
 	for _, net := range networks {
-		if net.Name == L1NetworkName || net.Name == L2NetworkName {
+		if net.Name == L1NetworkName() || net.Name == L2NetworkName() {
 			available = append(available, net.Name)
 		}
 	}
@@ -0,0 +1,27 @@
+package shared
+
+import (
+	"strconv"
+
+	"github.com/compose-network/local-testnet/internal/ports"
+	"github.com/docker/go-connections/nat"
+)
+
+// HostPorts lists the container ports of a service as host ports published on the same numbers by default.
+func HostPorts(service string, containerPorts ...int) []ports.Port {
+	hostPorts := make([]ports.Port, 0, len(containerPorts))
+	for _, port := range containerPorts {
+		hostPorts = append(hostPorts, ports.Port{Name: ports.Name(service, port), Default: port})
+	}
+	return hostPorts
+}
+
+// PortBindings publishes the container ports of a service on the host ports resolved for them.
+func PortBindings(service string, hostPorts ports.Map, containerPorts ...int) nat.PortMap {
+	bindings := make(nat.PortMap, len(containerPorts))
+	for _, port := range containerPorts {
+		hostPort := hostPorts.Get(ports.Name(service, port), port)
+		bindings[nat.Port(strconv.Itoa(port)+"/tcp")] = []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: strconv.Itoa(hostPort)}}
+	}
+	return bindings
+}
@@ -1,14 +1,46 @@
 package shared
 
-import "github.com/docker/docker/api/types/container"
-
-const (
-	ObservabilityNetworkName = "observability-net"
-	L1NetworkName            = "kt-localnet"
-	L2NetworkName            = "localnet-l2"
+import (
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/docker"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
 )
 
-var (
-	NetworkMode = container.NetworkMode(ObservabilityNetworkName)
-	Labels      = map[string]string{"stack": "localnet-observability"}
-)
+// ObservabilityNetworkName returns the network shared by the observability services of the selected instance.
+func ObservabilityNetworkName() string {
+	return configs.ScopedName("observability-net")
+}
+
+// L1NetworkName returns the network Kurtosis creates for the L1 enclave of the selected instance.
+func L1NetworkName() string {
+	return "kt-" + configs.Values.L1.Kurtosis.EnclaveName
+}
+
+// L2NetworkName returns the network of the L2 services of the selected instance.
+func L2NetworkName() string {
+	return docker.L2Network()
+}
+
+func NetworkMode() container.NetworkMode {
+	return container.NetworkMode(ObservabilityNetworkName())
+}
+
+func Labels() map[string]string {
+	return map[string]string{docker.StackLabelKey: docker.ObservabilityStack()}
+}
+
+// ContainerName returns the container name of an observability service in the selected instance.
+func ContainerName(service string) string {
+	return configs.ScopedName(service)
+}
+
+// NetworkingConfig joins the observability network under the plain service name,
+// which the service configurations in configs/ use as host name.
+func NetworkingConfig(service string) *network.NetworkingConfig {
+	return &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			ObservabilityNetworkName(): {Aliases: []string{service}},
+		},
+	}
+}
@@ -0,0 +1,183 @@
+package ports
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Mode selects how host ports are assigned.
+type Mode string
+
+const (
+	// ModeFixed publishes every service on its configured or default port and fails on conflicts.
+	ModeFixed Mode = "fixed"
+	// ModeAuto keeps free default ports and replaces ports in use with free ones.
+	ModeAuto Mode = "auto"
+)
+
+// FileName is the name of the port map file in the work directory of an instance.
+const FileName = "ports.json"
+
+type (
+	// Port is a host port published by a service.
+	Port struct {
+		// Name identifies the port, see Name.
+		Name    string
+		Default int
+	}
+
+	// Map assigns host ports by port name.
+	Map map[string]int
+)
+
+// Name returns the port name of a container port published by a service, e.g. "op-geth-a/8545".
+func Name(service string, containerPort int) string {
+	return fmt.Sprintf("%s/%d", service, containerPort)
+}
+
+// ParseMode converts a user supplied mode; empty selects ModeFixed.
+func ParseMode(name string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(name))); mode {
+	case "":
+		return ModeFixed, nil
+	case ModeFixed, ModeAuto:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown port mode '%s' (expected one of: %s, %s)", name, ModeFixed, ModeAuto)
+	}
+}
+
+// Get returns the port assigned to name, or def when the map has none.
+func (m Map) Get(name string, def int) int {
+	if port, ok := m[name]; ok {
+		return port
+	}
+	return def
+}
+
+// Resolve assigns a host port to every requested port.
+// In fixed mode each port keeps its default and every conflict is reported at once. In auto mode a port
+// in use is replaced by the one of the previous run, when still free, or by a free port picked by the OS.
+// Ports in owned are published by localnet's own containers and count as free.
+func Resolve(requested []Port, mode Mode, previous Map, owned []int) (Map, error) {
+	mode, err := ParseMode(string(mode))
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(Map, len(requested))
+	takenBy := make(map[int]string, len(requested))
+	isFree := func(port int) bool {
+		if _, taken := takenBy[port]; taken {
+			return false
+		}
+		return slices.Contains(owned, port) || Available(port)
+	}
+
+	var errs []error
+	for _, p := range requested {
+		port := p.Default
+		switch {
+		case mode == ModeAuto:
+			port, err = pickFree(isFree, previous[p.Name], p.Default)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+				continue
+			}
+		case takenBy[port] != "":
+			errs = append(errs, fmt.Errorf("host port %d is requested by both %s and %s", port, takenBy[port], p.Name))
+			continue
+		case !isFree(port):
+			errs = append(errs, fmt.Errorf("host port %d (%s) is already in use", port, p.Name))
+			continue
+		}
+
+		resolved[p.Name] = port
+		takenBy[port] = p.Name
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("host ports are not available. Free them, change them in the config or use the %s port mode: %w", ModeAuto, errors.Join(errs...))
+	}
+
+	return resolved, nil
+}
+
+// pickFree returns the first free candidate, falling back to a port picked by the OS.
+func pickFree(isFree func(int) bool, candidates ...int) (int, error) {
+	for _, port := range candidates {
+		if port > 0 && isFree(port) {
+			return port, nil
+		}
+	}
+
+	// The OS may hand out a port already assigned in this run, so retry a few times.
+	for range 10 {
+		listener, err := net.Listen("tcp", ":0")
+		if err != nil {
+			return 0, fmt.Errorf("failed to find a free port: %w", err)
+		}
+		port := listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+		if isFree(port) {
+			return port, nil
+		}
+	}
+
+	return 0, errors.New("failed to find a free port")
+}
+
+// Available reports whether a TCP port can be bound on all interfaces, as seen by this process.
+func Available(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
+
+// Load reads a port map file. A missing file yields an empty map.
+func Load(path string) (Map, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Map{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read port map %s: %w", path, err)
+	}
+
+	m := Map{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse port map %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Record merges m into the port map file, keeping the entries of other stacks.
+func Record(path string, m Map) error {
+	recorded, err := Load(path)
+	if err != nil {
+		return err
+	}
+	maps.Copy(recorded, m)
+
+	data, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode port map: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for port map: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write port map %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package up
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/compose-network/local-testnet/configs"
+	"github.com/compose-network/local-testnet/internal/l1"
+	"github.com/compose-network/local-testnet/internal/l2"
+	"github.com/compose-network/local-testnet/internal/observability"
+	"github.com/spf13/cobra"
+)
+
+const (
+	l1EndpointHostFlag = "l1-endpoint-host"
+	observabilityFlag  = "observability"
+	yesFlag            = "yes"
+	rebuildFlag        = "rebuild"
+)
+
+var CMD = &cobra.Command{
+	Use:   "up",
+	Short: "Start L1, L2 and observability in one go",
+	Long: `Starts the L1 enclave, points the L2 configuration at its endpoints and prefunded account,
+deploys the L2 network and finally starts the observability services.
+
+L1 settings from the config file (l1-chain-id, l1-el-url, l1-cl-url) are replaced by the values
+reported by the enclave. The wallet is only taken from the enclave when none is configured.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointHost, _ := cmd.Flags().GetString(l1EndpointHostFlag)
+		withObservability, _ := cmd.Flags().GetBool(observabilityFlag)
+		yes, _ := cmd.Flags().GetBool(yesFlag)
+		rebuild, _ := cmd.Flags().GetBool(rebuildFlag)
+
+		ctx := cmd.Context()
+
+		cfg := configs.Values.L2
+
+		if err := checkHostPorts(ctx, &cfg, withObservability); err != nil {
+			return err
+		}
+
+		l1Opts := l1.StartOptions{EndpointHost: endpointHost, Locked: configs.Values.Locked, PortMode: configs.Values.Ports.Mode}
+		if cfg.Wallet.Address != "" {
+			l1Opts.Fund = append(l1Opts.Fund, cfg.Wallet.Address)
+		}
+
+		slog.Info("starting l1")
+		l1Output, err := l1.Start(ctx, configs.Values.L1, l1Opts)
+		if err != nil {
+			return fmt.Errorf("error occurred starting l1: %w", err)
+		}
+
+		l1Output.ApplyTo(&cfg)
+		configs.Values.L2 = cfg
+
+		slog.With("l1_chain_id", cfg.L1ChainID, "l1_el_url", cfg.L1ElURL, "l1_cl_url", cfg.L1ClURL).
+			Info("l1 started. L2 config updated with l1 endpoints")
+
+		if err := l2.Run(ctx, cfg, l2.DeployOptions{PortMode: configs.Values.Ports.Mode, Yes: yes, Locked: configs.Values.Locked, Offline: configs.Values.Offline, Rebuild: rebuild}); err != nil {
+			return err
+		}
+
+		if !withObservability {
+			slog.Info("skipping observability services")
+			return nil
+		}
+
+		slog.Info("starting observability services")
+		if err := observability.Start(ctx, configs.Values.Ports.Mode); err != nil {
+			return fmt.Errorf("error occurred starting observability services: %w", err)
+		}
+
+		slog.Info("localnet is up")
+
+		return nil
+	},
+}
+
+// checkHostPorts resolves the host ports of every stack before anything is started, so a taken port
+// fails the run up front instead of after the L1 is up.
+func checkHostPorts(ctx context.Context, cfg *configs.L2, withObservability bool) error {
+	slog.Info("checking host ports")
+
+	if err := cfg.ValidateHostPorts(); err != nil {
+		return err
+	}
+	if _, err := l1.ResolveHostPorts(ctx, configs.Values.L1, configs.Values.Ports.Mode); err != nil {
+		return err
+	}
+
+	rootDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if err := l2.ResolveHostPorts(ctx, cfg, configs.Values.Ports.Mode, rootDir); err != nil {
+		return err
+	}
+
+	if withObservability {
+		if _, err := observability.ResolveHostPorts(ctx, configs.Values.Ports.Mode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	CMD.Flags().String(l1EndpointHostFlag, l1.DefaultEndpointHost, "Host used to reach the L1 enclave ports from the host and from L2 containers")
+	CMD.Flags().Bool(observabilityFlag, true, "Start the observability services after the L2 deployment")
+	CMD.Flags().Bool(yesFlag, false, "Deploy the L2 even if the L1 preflight finds an unknown chain or insufficient funds")
+	CMD.Flags().Bool(rebuildFlag, false, "Build the local L2 images even if they were built from the checked out sources")
+}